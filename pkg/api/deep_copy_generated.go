@@ -1191,6 +1191,13 @@ func deepCopy_api_GitBuildSource(in buildapi.GitBuildSource, out *buildapi.GitBu
 	out.Ref = in.Ref
 	out.HTTPProxy = in.HTTPProxy
 	out.HTTPSProxy = in.HTTPSProxy
+	out.LFS = in.LFS
+	if in.CloneDepth != nil {
+		out.CloneDepth = new(int)
+		*out.CloneDepth = *in.CloneDepth
+	} else {
+		out.CloneDepth = nil
+	}
 	return nil
 }
 
@@ -1217,6 +1224,7 @@ func deepCopy_api_ImageChangeTrigger(in buildapi.ImageChangeTrigger, out *builda
 	} else {
 		out.From = nil
 	}
+	out.Paused = in.Paused
 	return nil
 }
 
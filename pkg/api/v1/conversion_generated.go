@@ -1661,6 +1661,13 @@ func autoconvert_api_GitBuildSource_To_v1_GitBuildSource(in *buildapi.GitBuildSo
 	out.Ref = in.Ref
 	out.HTTPProxy = in.HTTPProxy
 	out.HTTPSProxy = in.HTTPSProxy
+	out.LFS = in.LFS
+	if in.CloneDepth != nil {
+		out.CloneDepth = new(int)
+		*out.CloneDepth = *in.CloneDepth
+	} else {
+		out.CloneDepth = nil
+	}
 	return nil
 }
 
@@ -1700,6 +1707,7 @@ func autoconvert_api_ImageChangeTrigger_To_v1_ImageChangeTrigger(in *buildapi.Im
 	} else {
 		out.From = nil
 	}
+	out.Paused = in.Paused
 	return nil
 }
 
@@ -2390,6 +2398,13 @@ func autoconvert_v1_GitBuildSource_To_api_GitBuildSource(in *apiv1.GitBuildSourc
 	out.Ref = in.Ref
 	out.HTTPProxy = in.HTTPProxy
 	out.HTTPSProxy = in.HTTPSProxy
+	out.LFS = in.LFS
+	if in.CloneDepth != nil {
+		out.CloneDepth = new(int)
+		*out.CloneDepth = *in.CloneDepth
+	} else {
+		out.CloneDepth = nil
+	}
 	return nil
 }
 
@@ -2429,6 +2444,7 @@ func autoconvert_v1_ImageChangeTrigger_To_api_ImageChangeTrigger(in *apiv1.Image
 	} else {
 		out.From = nil
 	}
+	out.Paused = in.Paused
 	return nil
 }
 
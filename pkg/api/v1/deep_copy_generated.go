@@ -1215,6 +1215,13 @@ func deepCopy_v1_GitBuildSource(in apiv1.GitBuildSource, out *apiv1.GitBuildSour
 	out.Ref = in.Ref
 	out.HTTPProxy = in.HTTPProxy
 	out.HTTPSProxy = in.HTTPSProxy
+	out.LFS = in.LFS
+	if in.CloneDepth != nil {
+		out.CloneDepth = new(int)
+		*out.CloneDepth = *in.CloneDepth
+	} else {
+		out.CloneDepth = nil
+	}
 	return nil
 }
 
@@ -1241,6 +1248,7 @@ func deepCopy_v1_ImageChangeTrigger(in apiv1.ImageChangeTrigger, out *apiv1.Imag
 	} else {
 		out.From = nil
 	}
+	out.Paused = in.Paused
 	return nil
 }
 
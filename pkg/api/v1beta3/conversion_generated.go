@@ -1670,6 +1670,13 @@ func autoconvert_api_GitBuildSource_To_v1beta3_GitBuildSource(in *buildapi.GitBu
 	out.Ref = in.Ref
 	out.HTTPProxy = in.HTTPProxy
 	out.HTTPSProxy = in.HTTPSProxy
+	out.LFS = in.LFS
+	if in.CloneDepth != nil {
+		out.CloneDepth = new(int)
+		*out.CloneDepth = *in.CloneDepth
+	} else {
+		out.CloneDepth = nil
+	}
 	return nil
 }
 
@@ -1709,6 +1716,7 @@ func autoconvert_api_ImageChangeTrigger_To_v1beta3_ImageChangeTrigger(in *builda
 	} else {
 		out.From = nil
 	}
+	out.Paused = in.Paused
 	return nil
 }
 
@@ -2399,6 +2407,13 @@ func autoconvert_v1beta3_GitBuildSource_To_api_GitBuildSource(in *apiv1beta3.Git
 	out.Ref = in.Ref
 	out.HTTPProxy = in.HTTPProxy
 	out.HTTPSProxy = in.HTTPSProxy
+	out.LFS = in.LFS
+	if in.CloneDepth != nil {
+		out.CloneDepth = new(int)
+		*out.CloneDepth = *in.CloneDepth
+	} else {
+		out.CloneDepth = nil
+	}
 	return nil
 }
 
@@ -2438,6 +2453,7 @@ func autoconvert_v1beta3_ImageChangeTrigger_To_api_ImageChangeTrigger(in *apiv1b
 	} else {
 		out.From = nil
 	}
+	out.Paused = in.Paused
 	return nil
 }
 
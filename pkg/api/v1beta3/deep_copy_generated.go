@@ -1223,6 +1223,13 @@ func deepCopy_v1beta3_GitBuildSource(in apiv1beta3.GitBuildSource, out *apiv1bet
 	out.Ref = in.Ref
 	out.HTTPProxy = in.HTTPProxy
 	out.HTTPSProxy = in.HTTPSProxy
+	out.LFS = in.LFS
+	if in.CloneDepth != nil {
+		out.CloneDepth = new(int)
+		*out.CloneDepth = *in.CloneDepth
+	} else {
+		out.CloneDepth = nil
+	}
 	return nil
 }
 
@@ -1249,6 +1256,7 @@ func deepCopy_v1beta3_ImageChangeTrigger(in apiv1beta3.ImageChangeTrigger, out *
 	} else {
 		out.From = nil
 	}
+	out.Paused = in.Paused
 	return nil
 }
 
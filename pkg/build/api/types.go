@@ -265,6 +265,14 @@ type GitBuildSource struct {
 
 	// HTTPSProxy is a proxy used to reach the git repository over https
 	HTTPSProxy string
+
+	// LFS indicates that Git Large File Storage objects should be fetched along
+	// with the repository
+	LFS bool
+
+	// CloneDepth is the number of commits to fetch for a shallow clone. If
+	// unset, the full history of the repository is cloned.
+	CloneDepth *int
 }
 
 // SourceControlUser defines the identity of a user of source control
@@ -410,6 +418,16 @@ const (
 	// BuildConfigLabelDeprecated was used as BuildConfigLabel before adding namespaces.
 	// We keep it for backward compatibility.
 	BuildConfigLabelDeprecated = "buildconfig"
+	// BuildConfigWebHookAnnotation is an annotation whose value is "true" when builds for a
+	// BuildConfig are expected to be started by a webhook.
+	BuildConfigWebHookAnnotation = "openshift.io/build-config.webhook"
+	// BuildConfigCacheAnnotation is an annotation whose value is "true" when builds for a
+	// BuildConfig are expected to reuse cached image layers.
+	BuildConfigCacheAnnotation = "openshift.io/build-config.cache"
+	// ExposeDockerSocketAcknowledgedAnnotation is an annotation whose value is "true" when
+	// the owner of a custom build acknowledges that exposing the docker socket to the
+	// builder grants it control of the node.
+	ExposeDockerSocketAcknowledgedAnnotation = "openshift.io/build.expose-docker-socket-acknowledged"
 )
 
 // BuildConfig is a template which can be used to create new builds.
@@ -457,6 +475,9 @@ type ImageChangeTrigger struct {
 	// will be used. Only one ImageChangeTrigger with an empty From reference is allowed in
 	// a build configuration.
 	From *kapi.ObjectReference
+
+	// Paused is true if this trigger is temporarily disabled.
+	Paused bool
 }
 
 // BuildTriggerPolicy describes a policy for a single trigger that results in a new Build.
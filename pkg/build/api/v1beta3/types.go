@@ -212,6 +212,14 @@ type GitBuildSource struct {
 
 	// HTTPSProxy is a proxy used to reach the git repository over https
 	HTTPSProxy string `json:"httpsProxy,omitempty" description:"specifies a https proxy to be used during git clone operations"`
+
+	// LFS indicates that Git Large File Storage objects should be fetched along
+	// with the repository
+	LFS bool `json:"lfs,omitempty" description:"fetch Git Large File Storage objects along with the repository"`
+
+	// CloneDepth is the number of commits to fetch for a shallow clone. If
+	// unset, the full history of the repository is cloned.
+	CloneDepth *int `json:"cloneDepth,omitempty" description:"number of commits to fetch for a shallow clone, the full history is cloned if unset"`
 }
 
 // SourceControlUser defines the identity of a user of source control
@@ -384,6 +392,9 @@ type ImageChangeTrigger struct {
 	// will be used. Only one ImageChangeTrigger with an empty From reference is allowed in
 	// a build configuration.
 	From *kapi.ObjectReference `json:"from,omitempty" description:"reference to an ImageStreamTag that will trigger the build"`
+
+	// Paused is true if this trigger is temporarily disabled.
+	Paused bool `json:"paused,omitempty" description:"true if this trigger is temporarily disabled"`
 }
 
 // BuildTriggerPolicy describes a policy for a single trigger that results in a new Build.
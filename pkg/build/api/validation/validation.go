@@ -1,14 +1,19 @@
 package validation
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"path"
+	"regexp"
 	"strings"
 
+	"github.com/docker/docker/builder/command"
+	"github.com/docker/docker/builder/parser"
 	kapi "k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/validation"
 	"k8s.io/kubernetes/pkg/util/fielderrors"
+	"k8s.io/kubernetes/pkg/util/sets"
 	kvalidation "k8s.io/kubernetes/pkg/util/validation"
 
 	oapi "github.com/openshift/origin/pkg/api"
@@ -17,11 +22,28 @@ import (
 	imageapi "github.com/openshift/origin/pkg/image/api"
 )
 
-// ValidateBuild tests required fields for a Build.
+// ValidateBuild tests required fields for a Build. Builds carry no build
+// history limits (those belong to the BuildConfig that prunes them), so there
+// are no limit fields to check here.
 func ValidateBuild(build *buildapi.Build) fielderrors.ValidationErrorList {
 	allErrs := fielderrors.ValidationErrorList{}
 	allErrs = append(allErrs, validation.ValidateObjectMeta(&build.ObjectMeta, true, validation.NameIsDNSSubdomain).Prefix("metadata")...)
 	allErrs = append(allErrs, validateBuildSpec(&build.Spec).Prefix("spec")...)
+	// builds started from a config were acknowledged on the config
+	if build.Status.Config == nil {
+		allErrs = append(allErrs, validateExposeDockerSocketAcknowledged(&build.Spec.Strategy, build.Annotations)...)
+	}
+	return allErrs
+}
+
+// ValidateBuildList tests required fields for every Build of the list. The
+// errors of each Build are prefixed with its index in the list, e.g.
+// items[2].spec.output.to.
+func ValidateBuildList(list *buildapi.BuildList) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	for i := range list.Items {
+		allErrs = append(allErrs, ValidateBuild(&list.Items[i]).PrefixIndex(i).Prefix("items")...)
+	}
 	return allErrs
 }
 
@@ -54,16 +76,63 @@ func refKey(namespace string, ref *kapi.ObjectReference) string {
 	return fmt.Sprintf("%s/%s", ns, ref.Name)
 }
 
+// withEffectiveNamespace adds the namespace/name key of the image stream tag an
+// ImageChange trigger refers to, as computed by refKey, to the details of its
+// errors. A From without a namespace watches the namespace of the config, which
+// is easy to miss when reading the error.
+func withEffectiveNamespace(errs fielderrors.ValidationErrorList, key string) {
+	for _, err := range errs {
+		if err, ok := err.(*fielderrors.ValidationError); ok {
+			detail := fmt.Sprintf("the trigger watches %s", key)
+			if len(err.Detail) != 0 {
+				detail = fmt.Sprintf("%s (%s)", err.Detail, detail)
+			}
+			err.Detail = detail
+		}
+	}
+}
+
+// MinCompletionDeadlineSeconds is the shortest CompletionDeadlineSeconds that
+// leaves a build time to start. Shorter deadlines are warned about.
+const MinCompletionDeadlineSeconds = 10
+
+// MaxBuildConfigTriggers is the maximum number of triggers a BuildConfig may
+// define. Zero means there is no limit.
+var MaxBuildConfigTriggers int
+
 // ValidateBuildConfig tests required fields for a Build.
 func ValidateBuildConfig(config *buildapi.BuildConfig) fielderrors.ValidationErrorList {
+	return ValidateBuildConfigWithResolver(config, nil)
+}
+
+// ReferenceResolver reports whether objects referenced by a BuildConfig exist.
+// Validation has no access to storage, so callers that do, such as admission,
+// may provide one to reject dangling references.
+type ReferenceResolver interface {
+	// ImageStreamTagExists returns true if the image stream tag name exists in
+	// namespace.
+	ImageStreamTagExists(namespace, name string) (bool, error)
+	// SecretExists returns true if the secret name exists in namespace.
+	SecretExists(namespace, name string) (bool, error)
+}
+
+// ValidateBuildConfigWithResolver tests required fields for a Build like
+// ValidateBuildConfig. If resolver is not nil, referenced image stream tags and
+// secrets must also exist.
+func ValidateBuildConfigWithResolver(config *buildapi.BuildConfig, resolver ReferenceResolver) fielderrors.ValidationErrorList {
 	allErrs := fielderrors.ValidationErrorList{}
 	allErrs = append(allErrs, validation.ValidateObjectMeta(&config.ObjectMeta, true, validation.NameIsDNSSubdomain).Prefix("metadata")...)
 
+	if MaxBuildConfigTriggers > 0 && len(config.Spec.Triggers) > MaxBuildConfigTriggers {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("triggers", len(config.Spec.Triggers), fmt.Sprintf("may not define more than %d triggers", MaxBuildConfigTriggers)))
+	}
+
 	// image change triggers that refer
 	fromRefs := map[string]struct{}{}
 	for i, trg := range config.Spec.Triggers {
-		allErrs = append(allErrs, validateTrigger(&trg).PrefixIndex(i).Prefix("triggers")...)
+		trgErrs := validateTrigger(&trg)
 		if trg.Type != buildapi.ImageChangeBuildTriggerType || trg.ImageChange == nil {
+			allErrs = append(allErrs, trgErrs.PrefixIndex(i).Prefix("triggers")...)
 			continue
 		}
 		from := trg.ImageChange.From
@@ -71,14 +140,31 @@ func ValidateBuildConfig(config *buildapi.BuildConfig) fielderrors.ValidationErr
 			from = buildutil.GetImageStreamForStrategy(config.Spec.Strategy)
 		}
 		fromKey := refKey(config.Namespace, from)
+		if trg.ImageChange.From != nil && fromKey != "nil" {
+			withEffectiveNamespace(trgErrs, fromKey)
+		}
+		allErrs = append(allErrs, trgErrs.PrefixIndex(i).Prefix("triggers")...)
 		_, exists := fromRefs[fromKey]
 		if exists {
-			allErrs = append(allErrs, fielderrors.NewFieldInvalid("triggers", config.Spec.Triggers, "multiple ImageChange triggers refer to the same image stream tag"))
+			msg := "multiple ImageChange triggers refer to the same image stream tag"
+			if fromKey != "nil" {
+				msg = fmt.Sprintf("%s %s", msg, fromKey)
+			}
+			// the triggers are not echoed, they hold webhook secrets
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("triggers", fromKey, msg))
 		}
 		fromRefs[fromKey] = struct{}{}
 	}
 
 	allErrs = append(allErrs, validateBuildSpec(&config.Spec.BuildSpec).Prefix("spec")...)
+	allErrs = append(allErrs, validateExposeDockerSocketAcknowledged(&config.Spec.Strategy, config.Annotations)...)
+
+	// a binary build has no repository to resolve a git revision against. Builds
+	// instantiated from a binary may still record the commit of the uploaded
+	// content, so this is only enforced on the config.
+	if config.Spec.Source.Type == buildapi.BuildSourceBinary && isGitRevision(config.Spec.Revision) {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("spec.revision.type", config.Spec.Revision.Type, "a Git revision may not be specified when the source type is Binary"))
+	}
 
 	// validate ImageChangeTriggers of DockerStrategy builds
 	strategy := config.Spec.BuildSpec.Strategy
@@ -90,13 +176,542 @@ func ValidateBuildConfig(config *buildapi.BuildConfig) fielderrors.ValidationErr
 		}
 	}
 
+	if resolver != nil {
+		allErrs = append(allErrs, validateReferencesExist(config, resolver)...)
+	}
+
+	return allErrs
+}
+
+// validateReferencesExist checks with resolver that the image stream tags the
+// config builds from or is triggered by, and the secrets it uses, exist. The
+// output image stream tag is not checked, since it is created by the build.
+func validateReferencesExist(config *buildapi.BuildConfig, resolver ReferenceResolver) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	namespace := config.Namespace
+
+	checkTag := func(field string, ref *kapi.ObjectReference) {
+		if ref == nil || ref.Kind != "ImageStreamTag" || len(ref.Name) == 0 {
+			return
+		}
+		ns := ref.Namespace
+		if len(ns) == 0 {
+			ns = namespace
+		}
+		if exists, err := resolver.ImageStreamTagExists(ns, ref.Name); err != nil {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid(field, ref.Name, fmt.Sprintf("unable to check that the image stream tag exists: %v", err)))
+		} else if !exists {
+			allErrs = append(allErrs, fielderrors.NewFieldNotFound(field, ref.Name))
+		}
+	}
+	checkSecret := func(field string, ref *kapi.LocalObjectReference) {
+		if ref == nil || len(ref.Name) == 0 {
+			return
+		}
+		if exists, err := resolver.SecretExists(namespace, ref.Name); err != nil {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid(field, ref.Name, fmt.Sprintf("unable to check that the secret exists: %v", err)))
+		} else if !exists {
+			allErrs = append(allErrs, fielderrors.NewFieldNotFound(field, ref.Name))
+		}
+	}
+
+	spec := &config.Spec.BuildSpec
+	checkSecret("spec.source.sourceSecret.name", spec.Source.SourceSecret)
+	checkSecret("spec.output.pushSecret.name", spec.Output.PushSecret)
+	switch strategy := spec.Strategy; {
+	case strategy.SourceStrategy != nil:
+		checkTag("spec.strategy.stiStrategy.from.name", &strategy.SourceStrategy.From)
+		checkSecret("spec.strategy.stiStrategy.pullSecret.name", strategy.SourceStrategy.PullSecret)
+	case strategy.DockerStrategy != nil:
+		checkTag("spec.strategy.dockerStrategy.from.name", strategy.DockerStrategy.From)
+		checkSecret("spec.strategy.dockerStrategy.pullSecret.name", strategy.DockerStrategy.PullSecret)
+	case strategy.CustomStrategy != nil:
+		checkTag("spec.strategy.customStrategy.from.name", &strategy.CustomStrategy.From)
+		checkSecret("spec.strategy.customStrategy.pullSecret.name", strategy.CustomStrategy.PullSecret)
+		for i := range strategy.CustomStrategy.Secrets {
+			checkSecret(fmt.Sprintf("spec.strategy.customStrategy.secrets[%d].secretSource.name", i), &strategy.CustomStrategy.Secrets[i].SecretSource)
+		}
+	}
+	for i, trigger := range config.Spec.Triggers {
+		if trigger.Type == buildapi.ImageChangeBuildTriggerType && trigger.ImageChange != nil {
+			checkTag(fmt.Sprintf("triggers[%d].imageChange.from.name", i), trigger.ImageChange.From)
+		}
+	}
 	return allErrs
 }
 
+// TemplateConstraints are declared by the template a BuildConfig was created
+// from, and are checked by ValidateBuildConfigAgainstTemplateConstraints to
+// detect configs that drifted from their template.
+type TemplateConstraints struct {
+	// AllowedStrategies are the strategy types the config may use. If empty,
+	// any strategy is allowed.
+	AllowedStrategies []buildapi.BuildStrategyType
+	// RequiredTriggers are the trigger types the config must define.
+	RequiredTriggers []buildapi.BuildTriggerType
+}
+
+// ValidateBuildConfigAgainstTemplateConstraints tests that the config still
+// satisfies the constraints of the template it was created from. It does not
+// validate the config itself, see ValidateBuildConfig.
+func ValidateBuildConfigAgainstTemplateConstraints(config *buildapi.BuildConfig, constraints *TemplateConstraints) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	if constraints == nil {
+		return allErrs
+	}
+	if len(constraints.AllowedStrategies) != 0 {
+		allowed := sets.NewString()
+		for _, strategyType := range constraints.AllowedStrategies {
+			allowed.Insert(string(strategyType))
+		}
+		if strategyType := config.Spec.Strategy.Type; !allowed.Has(string(strategyType)) {
+			allErrs = append(allErrs, fielderrors.NewFieldValueNotSupported("spec.strategy.type", strategyType, allowed.List()))
+		}
+	}
+	for _, triggerType := range constraints.RequiredTriggers {
+		if countTriggers(config.Spec.Triggers, triggerType) == 0 {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("triggers", triggerType, fmt.Sprintf("a %s trigger is required by the template the build config was created from", triggerType)))
+		}
+	}
+	return allErrs
+}
+
+// ExplainBuildConfigDefaults returns the references that validation resolves
+// for fields left unset on the config, keyed by field path. An ImageChange
+// trigger without a From watches the image stream tag of the strategy, which
+// is reported as namespace/name.
+func ExplainBuildConfigDefaults(config *buildapi.BuildConfig) map[string]string {
+	defaults := map[string]string{}
+	for i, trg := range config.Spec.Triggers {
+		if trg.Type != buildapi.ImageChangeBuildTriggerType || trg.ImageChange == nil || trg.ImageChange.From != nil {
+			continue
+		}
+		from := buildutil.GetImageStreamForStrategy(config.Spec.Strategy)
+		if from == nil || from.Kind != "ImageStreamTag" {
+			continue
+		}
+		defaults[fmt.Sprintf("spec.triggers[%d].imageChange.from", i)] = refKey(config.Namespace, from)
+	}
+	return defaults
+}
+
+// WarningCode identifies the kind of a BuildConfigWarning.
+type WarningCode string
+
+// The codes of the warnings returned by ValidateBuildConfigWithWarnings.
+const (
+	WarningWebHookAnnotationWithoutTrigger WarningCode = "WebHookAnnotationWithoutTrigger"
+	WarningAllTriggersPaused               WarningCode = "AllTriggersPaused"
+	WarningContextDirNormalized            WarningCode = "ContextDirNormalized"
+	WarningDivergentProxies                WarningCode = "DivergentProxies"
+	WarningNoCacheWithCacheAnnotation      WarningCode = "NoCacheWithCacheAnnotation"
+	WarningFloatingFromTag                 WarningCode = "FloatingFromTag"
+	WarningRedundantPullSecret             WarningCode = "RedundantPullSecret"
+	WarningCrossNamespaceOutput            WarningCode = "CrossNamespaceOutput"
+	WarningReservedOutputNamespace         WarningCode = "ReservedOutputNamespace"
+	WarningOutputSameAsFrom                WarningCode = "OutputSameAsFrom"
+	WarningShallowCloneCommitRef           WarningCode = "ShallowCloneCommitRef"
+	WarningMultipleGitHubWebHooks          WarningCode = "MultipleGitHubWebHooks"
+	WarningOutputWithoutRegistry           WarningCode = "OutputWithoutRegistry"
+	WarningShortCompletionDeadline         WarningCode = "ShortCompletionDeadline"
+	WarningBinarySourceSecret              WarningCode = "BinarySourceSecret"
+	WarningContextDirLooksLikeFile         WarningCode = "ContextDirLooksLikeFile"
+	WarningNoOutput                        WarningCode = "NoOutput"
+	WarningProxyCredentials                WarningCode = "ProxyCredentials"
+	WarningPinnedRefWithTrigger            WarningCode = "PinnedRefWithTrigger"
+	WarningCrossNamespacePushSecret        WarningCode = "CrossNamespacePushSecret"
+	WarningReservedLabel                   WarningCode = "ReservedLabel"
+)
+
+// BuildConfigWarning is an advisory diagnostic about a BuildConfig or Build
+// that is valid but unlikely to behave the way the user intended.
+type BuildConfigWarning struct {
+	// Field is the path of the field the warning is about.
+	Field string
+	// Message describes the problem to the user.
+	Message string
+	// Code identifies the kind of warning, so tools can recognize it without
+	// parsing the message.
+	Code WarningCode
+}
+
+// String returns the warning in the form "<field>: <message>".
+func (w BuildConfigWarning) String() string {
+	return w.Field + ": " + w.Message
+}
+
+// ValidateBuildConfigWithWarnings validates the config like ValidateBuildConfig
+// and also returns the warnings about it. The warnings are gathered before the
+// config is validated, since validation normalizes some fields in place.
+func ValidateBuildConfigWithWarnings(config *buildapi.BuildConfig) (fielderrors.ValidationErrorList, []BuildConfigWarning) {
+	warnings := buildConfigWarnings(config)
+	return ValidateBuildConfig(config), warnings
+}
+
+// ValidateBuildConfigWarnings returns advisory messages about a BuildConfig
+// that is valid but unlikely to behave the way the user intended. Validation
+// normalizes some fields in place, so warnings must be gathered before the
+// config is validated.
+func ValidateBuildConfigWarnings(config *buildapi.BuildConfig) []string {
+	return warningStrings(buildConfigWarnings(config))
+}
+
+func buildConfigWarnings(config *buildapi.BuildConfig) []BuildConfigWarning {
+	warnings := []BuildConfigWarning{}
+	if config.Annotations[buildapi.BuildConfigWebHookAnnotation] == "true" && !hasWebHookTrigger(config.Spec.Triggers) {
+		warnings = append(warnings, BuildConfigWarning{"spec.triggers", fmt.Sprintf("the %s annotation is set but no GitHub or Generic webhook trigger is defined", buildapi.BuildConfigWebHookAnnotation), WarningWebHookAnnotationWithoutTrigger})
+	}
+	if n := countTriggers(config.Spec.Triggers, buildapi.GitHubWebHookBuildTriggerType); n > 1 {
+		warnings = append(warnings, BuildConfigWarning{"spec.triggers", fmt.Sprintf("%d GitHub webhook triggers are defined, a repository usually needs only one", n), WarningMultipleGitHubWebHooks})
+	}
+	warnings = append(warnings, reservedLabelWarnings(config.Labels, reservedBuildConfigLabels)...)
+	if allTriggersPaused(config.Spec.Triggers) {
+		warnings = append(warnings, BuildConfigWarning{"spec.triggers", "all triggers are paused, so builds will only start when requested manually", WarningAllTriggersPaused})
+	}
+	if git := config.Spec.Source.Git; git != nil && fullCommitExp.MatchString(git.Ref) {
+		if triggerType, ok := activeRebuildTrigger(config.Spec.Triggers); ok {
+			warnings = append(warnings, BuildConfigWarning{"spec.source.git.ref", fmt.Sprintf("the ref is a fixed commit, so builds started by the %s trigger rebuild the same source and code changes are never picked up", triggerType), WarningPinnedRefWithTrigger})
+		}
+	}
+	warnings = append(warnings, prefixWarnings("spec", buildSpecWarnings(&config.Spec.BuildSpec, &config.ObjectMeta))...)
+	return warnings
+}
+
+// activeRebuildTrigger returns the type of the first ConfigChange or unpaused
+// ImageChange trigger, which start builds without a change to the source.
+func activeRebuildTrigger(triggers []buildapi.BuildTriggerPolicy) (buildapi.BuildTriggerType, bool) {
+	for _, trigger := range triggers {
+		switch trigger.Type {
+		case buildapi.ConfigChangeBuildTriggerType:
+			return trigger.Type, true
+		case buildapi.ImageChangeBuildTriggerType:
+			if trigger.ImageChange != nil && !trigger.ImageChange.Paused {
+				return trigger.Type, true
+			}
+		}
+	}
+	return "", false
+}
+
+// countTriggers returns the number of triggers of the given type.
+func countTriggers(triggers []buildapi.BuildTriggerPolicy, triggerType buildapi.BuildTriggerType) int {
+	n := 0
+	for _, trigger := range triggers {
+		if trigger.Type == triggerType {
+			n++
+		}
+	}
+	return n
+}
+
+// allTriggersPaused returns true if there is at least one trigger and every
+// trigger is a paused ImageChange trigger.
+func allTriggersPaused(triggers []buildapi.BuildTriggerPolicy) bool {
+	if len(triggers) == 0 {
+		return false
+	}
+	for _, trigger := range triggers {
+		if trigger.Type != buildapi.ImageChangeBuildTriggerType || trigger.ImageChange == nil || !trigger.ImageChange.Paused {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateBuildWarnings returns advisory messages about a Build that is valid
+// but unlikely to behave the way the user intended. Like
+// ValidateBuildConfigWarnings, it must be called before the build is validated.
+func ValidateBuildWarnings(build *buildapi.Build) []string {
+	warnings := reservedLabelWarnings(build.Labels, reservedBuildLabels)
+	warnings = append(warnings, prefixWarnings("spec", buildSpecWarnings(&build.Spec, &build.ObjectMeta))...)
+	return warningStrings(warnings)
+}
+
+// reservedBuildConfigLabels are the labels the build system sets on the builds
+// of a config, and on their pods. The labels of a config are copied to its
+// builds, where these are overwritten.
+var reservedBuildConfigLabels = sets.NewString(buildapi.BuildConfigLabel, buildapi.BuildConfigLabelDeprecated, buildapi.BuildLabel)
+
+// reservedBuildLabels are the labels the build system sets on the pod of a
+// build. The config labels are set on builds by the build system itself, so
+// they are not reserved for builds.
+var reservedBuildLabels = sets.NewString(buildapi.BuildLabel)
+
+// reservedLabelWarnings warns about labels using one of the reserved keys,
+// whose values are replaced by the build system.
+func reservedLabelWarnings(labels map[string]string, reserved sets.String) []BuildConfigWarning {
+	warnings := []BuildConfigWarning{}
+	for _, key := range reserved.List() {
+		if _, ok := labels[key]; ok {
+			warnings = append(warnings, BuildConfigWarning{"metadata.labels", fmt.Sprintf("the %s label is set by the build system and the value will be replaced", key), WarningReservedLabel})
+		}
+	}
+	return warnings
+}
+
+// warningStrings returns the messages of the warnings prefixed by their field.
+func warningStrings(warnings []BuildConfigWarning) []string {
+	messages := []string{}
+	for _, warning := range warnings {
+		messages = append(messages, warning.String())
+	}
+	return messages
+}
+
+// prefixWarnings adds a field path prefix to every warning.
+func prefixWarnings(prefix string, warnings []BuildConfigWarning) []BuildConfigWarning {
+	for i := range warnings {
+		warnings[i].Field = prefix + "." + warnings[i].Field
+	}
+	return warnings
+}
+
+func buildSpecWarnings(spec *buildapi.BuildSpec, meta *kapi.ObjectMeta) []BuildConfigWarning {
+	warnings := []BuildConfigWarning{}
+	warnings = append(warnings, prefixWarnings("source", sourceWarnings(&spec.Source))...)
+	if spec.Strategy.Type == buildapi.DockerBuildStrategyType && spec.Strategy.DockerStrategy != nil {
+		warnings = append(warnings, prefixWarnings("strategy.dockerStrategy", dockerStrategyWarnings(spec.Strategy.DockerStrategy, meta.Annotations))...)
+	}
+	if spec.Strategy.Type == buildapi.SourceBuildStrategyType && spec.Strategy.SourceStrategy != nil {
+		warnings = append(warnings, prefixWarnings("strategy.sourceStrategy", sourceStrategyWarnings(spec.Strategy.SourceStrategy, meta.Namespace))...)
+	}
+	if d := spec.CompletionDeadlineSeconds; d != nil && *d > 0 && *d < MinCompletionDeadlineSeconds {
+		warnings = append(warnings, BuildConfigWarning{"completionDeadlineSeconds", fmt.Sprintf("a deadline of %d seconds is shorter than the %d seconds a build usually needs to start, so the build will likely be killed", *d, MinCompletionDeadlineSeconds), WarningShortCompletionDeadline})
+	}
+	if spec.Output.To != nil {
+		warnings = append(warnings, prefixWarnings("output.to", outputWarnings(spec.Output.To, meta.Namespace))...)
+		if to, secret := spec.Output.To, spec.Output.PushSecret; to.Kind == "ImageStreamTag" && len(to.Namespace) != 0 && to.Namespace != meta.Namespace && secret != nil && len(secret.Name) != 0 {
+			warnings = append(warnings, BuildConfigWarning{"output.pushSecret", fmt.Sprintf("the push secret %q must exist in the build's namespace %q, not in the output namespace %q", secret.Name, meta.Namespace, to.Namespace), WarningCrossNamespacePushSecret})
+		}
+		if from := buildutil.GetImageStreamForStrategy(spec.Strategy); from != nil && isSameImageDigest(from, spec.Output.To) {
+			warnings = append(warnings, BuildConfigWarning{"output.to", fmt.Sprintf("%q is the same image as the strategy from, so the build would not produce a new image", spec.Output.To.Name), WarningOutputSameAsFrom})
+		}
+	} else if isMissingOutput(spec) && !RejectMissingOutput {
+		warnings = append(warnings, BuildConfigWarning{"output.to", "no output is set, so the built image is discarded, which is only expected for builds that test the source", WarningNoOutput})
+	}
+	return warnings
+}
+
+// RejectMissingOutput makes validation reject Source and Docker strategy
+// builds without an output instead of only warning about them.
+var RejectMissingOutput bool
+
+// isMissingOutput returns true if a Source or Docker strategy build has no
+// output to push its image to. Custom builders may push the image themselves,
+// so they are exempt.
+func isMissingOutput(spec *buildapi.BuildSpec) bool {
+	if spec.Output.To != nil {
+		return false
+	}
+	return spec.Strategy.Type == buildapi.SourceBuildStrategyType || spec.Strategy.Type == buildapi.DockerBuildStrategyType
+}
+
+// isSameImageDigest returns true if both references name the same image
+// repository by the same digest.
+func isSameImageDigest(a, b *kapi.ObjectReference) bool {
+	if a.Kind != "DockerImage" || b.Kind != "DockerImage" {
+		return false
+	}
+	aRef, err := imageapi.ParseDockerImageReference(a.Name)
+	if err != nil || len(aRef.ID) == 0 {
+		return false
+	}
+	bRef, err := imageapi.ParseDockerImageReference(b.Name)
+	if err != nil {
+		return false
+	}
+	return aRef.ID == bRef.ID && aRef.AsRepository().Equal(bRef.AsRepository())
+}
+
+// sourceStrategyWarnings warns about a pull secret for a builder image that
+// comes from an image stream in the build's own namespace, which the builder
+// service account can already pull.
+func sourceStrategyWarnings(strategy *buildapi.SourceBuildStrategy, namespace string) []BuildConfigWarning {
+	warnings := []BuildConfigWarning{}
+	from := strategy.From
+	if strategy.PullSecret != nil && from.Kind == "ImageStreamTag" && (len(from.Namespace) == 0 || from.Namespace == namespace) {
+		warnings = append(warnings, BuildConfigWarning{"pullSecret", "a pull secret is usually unnecessary for an ImageStreamTag in the same namespace", WarningRedundantPullSecret})
+	}
+	return warnings
+}
+
+// ReservedOutputNamespaces are namespaces that builds from other namespaces are
+// not expected to push their output to.
+var ReservedOutputNamespaces = sets.NewString("openshift", "openshift-infra", "kube-system")
+
+// outputWarnings warns about pushes to an image stream in another namespace,
+// which need permissions on that namespace that validation cannot check.
+func outputWarnings(to *kapi.ObjectReference, namespace string) []BuildConfigWarning {
+	warnings := []BuildConfigWarning{}
+	switch to.Kind {
+	case "DockerImage":
+		if ref, err := imageapi.ParseDockerImageReference(to.Name); err == nil && len(ref.Registry) == 0 {
+			warnings = append(warnings, BuildConfigWarning{"name", fmt.Sprintf("%q has no registry host and is pushed to Docker Hub, prefix it with the registry to push to", to.Name), WarningOutputWithoutRegistry})
+		}
+	case "ImageStreamTag":
+		if len(to.Namespace) == 0 || to.Namespace == namespace {
+			break
+		}
+		if ReservedOutputNamespaces.Has(to.Namespace) {
+			warnings = append(warnings, BuildConfigWarning{"namespace", fmt.Sprintf("%q is a reserved namespace, user builds are not expected to push to it", to.Namespace), WarningReservedOutputNamespace})
+		} else {
+			warnings = append(warnings, BuildConfigWarning{"namespace", fmt.Sprintf("pushing to namespace %q requires the builder service account to have access to it", to.Namespace), WarningCrossNamespaceOutput})
+		}
+	}
+	return warnings
+}
+
+func gitSourceWarnings(git *buildapi.GitBuildSource) []BuildConfigWarning {
+	warnings := []BuildConfigWarning{}
+	if git.CloneDepth != nil && *git.CloneDepth >= 1 && fullCommitExp.MatchString(git.Ref) {
+		warnings = append(warnings, BuildConfigWarning{"ref", fmt.Sprintf("a shallow clone of depth %d can only check out this commit if it is one of the latest %d commits of a branch", *git.CloneDepth, *git.CloneDepth), WarningShallowCloneCommitRef})
+	}
+	if strings.HasPrefix(git.URI, "https://") && len(git.HTTPProxy) != 0 && len(git.HTTPSProxy) != 0 {
+		httpHost, httpsHost := proxyHost(git.HTTPProxy), proxyHost(git.HTTPSProxy)
+		if len(httpHost) != 0 && len(httpsHost) != 0 && httpHost != httpsHost {
+			warnings = append(warnings, BuildConfigWarning{"httpProxy", fmt.Sprintf("proxy host %q differs from httpsProxy host %q, and only httpsProxy is used for an https uri", httpHost, httpsHost), WarningDivergentProxies})
+		}
+	}
+	if hasProxyCredentials(git.HTTPProxy) {
+		warnings = append(warnings, BuildConfigWarning{"httpProxy", "the proxy url includes credentials, which anyone who can read the build can see, consider providing them through a secret", WarningProxyCredentials})
+	}
+	if hasProxyCredentials(git.HTTPSProxy) {
+		warnings = append(warnings, BuildConfigWarning{"httpsProxy", "the proxy url includes credentials, which anyone who can read the build can see, consider providing them through a secret", WarningProxyCredentials})
+	}
+	return warnings
+}
+
+// hasProxyCredentials returns true if the proxy url includes user info.
+func hasProxyCredentials(proxy string) bool {
+	u, err := url.Parse(proxy)
+	return err == nil && u.User != nil
+}
+
+// sanitizeURL returns the url without credentials, so it can be reported
+// safely. A url that does not parse is returned unchanged by SanitizeGitURI, so
+// anything before an @ in it is dropped as well.
+func sanitizeURL(uri string) string {
+	uri = buildutil.SanitizeGitURI(uri)
+	if i := strings.LastIndex(uri, "@"); i != -1 {
+		scheme := ""
+		if j := strings.Index(uri, "://"); j != -1 && j < i {
+			scheme = uri[:j+3]
+		}
+		uri = scheme + uri[i+1:]
+	}
+	return uri
+}
+
+// fullCommitExp matches a full git commit SHA.
+var fullCommitExp = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// proxyHost returns the host name of the proxy url, or an empty string if it
+// cannot be parsed.
+func proxyHost(proxy string) string {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return ""
+	}
+	if i := strings.LastIndex(u.Host, ":"); i != -1 {
+		return u.Host[:i]
+	}
+	return u.Host
+}
+
+func dockerStrategyWarnings(strategy *buildapi.DockerBuildStrategy, annotations map[string]string) []BuildConfigWarning {
+	warnings := []BuildConfigWarning{}
+	if strategy.NoCache && annotations[buildapi.BuildConfigCacheAnnotation] == "true" {
+		warnings = append(warnings, BuildConfigWarning{"noCache", fmt.Sprintf("the build will not use cached layers even though the %s annotation is set", buildapi.BuildConfigCacheAnnotation), WarningNoCacheWithCacheAnnotation})
+	}
+	if strategy.From != nil && isFloatingTag(strategy.From) {
+		warnings = append(warnings, BuildConfigWarning{"from", fmt.Sprintf("%q follows the %q tag, refer to the image by digest for reproducible builds", strategy.From.Name, imageapi.DefaultImageTag), WarningFloatingFromTag})
+	}
+	return warnings
+}
+
+// isFloatingTag returns true if the reference resolves through the default
+// tag, which moves whenever a new image is pushed, rather than a digest.
+func isFloatingTag(ref *kapi.ObjectReference) bool {
+	switch ref.Kind {
+	case "DockerImage":
+		image, err := imageapi.ParseDockerImageReference(ref.Name)
+		if err != nil {
+			return false
+		}
+		return len(image.ID) == 0 && (len(image.Tag) == 0 || image.Tag == imageapi.DefaultImageTag)
+	case "ImageStreamTag":
+		_, tag, ok := imageapi.SplitImageStreamTag(ref.Name)
+		return ok && tag == imageapi.DefaultImageTag
+	}
+	return false
+}
+
+func sourceWarnings(source *buildapi.BuildSource) []BuildConfigWarning {
+	warnings := []BuildConfigWarning{}
+	if source.Git != nil {
+		warnings = append(warnings, prefixWarnings("git", gitSourceWarnings(source.Git))...)
+	}
+	if isBinaryWithSourceSecret(source) && !RejectBinarySourceSecret {
+		warnings = append(warnings, BuildConfigWarning{"sourceSecret", "a binary build has no remote repository to authenticate to, so the source secret is not used", WarningBinarySourceSecret})
+	}
+	if len(source.ContextDir) != 0 {
+		// dropping a trailing slash doesn't change which directory is used
+		if cleaned, err := NormalizeContextDir(source.ContextDir); err == nil && cleaned != strings.TrimSuffix(source.ContextDir, "/") {
+			warnings = append(warnings, BuildConfigWarning{"contextDir", fmt.Sprintf("%q will be normalized to %q", source.ContextDir, cleaned), WarningContextDirNormalized})
+		}
+		if ext := path.Ext(strings.TrimSuffix(source.ContextDir, "/")); fileExtensions.Has(strings.ToLower(ext)) {
+			warnings = append(warnings, BuildConfigWarning{"contextDir", fmt.Sprintf("%q looks like a file, the context dir must be a directory", source.ContextDir), WarningContextDirLooksLikeFile})
+		}
+	}
+	return warnings
+}
+
+// fileExtensions are extensions of source and archive files that a context dir
+// is not expected to have. Whether the directory exists can't be checked without
+// the source, but a name with one of these is most likely a file.
+var fileExtensions = sets.NewString(
+	".go", ".java", ".js", ".py", ".rb", ".php", ".pl", ".sh",
+	".json", ".yaml", ".yml", ".xml", ".txt", ".md",
+	".jar", ".war", ".ear", ".zip", ".tar", ".gz", ".tgz",
+)
+
+// RejectBinarySourceSecret makes validation reject binary sources that set a
+// source secret instead of only warning about them.
+var RejectBinarySourceSecret bool
+
+// isBinaryWithSourceSecret returns true if the source is built from a binary
+// payload rather than a git repository and still sets a source secret.
+func isBinaryWithSourceSecret(source *buildapi.BuildSource) bool {
+	return source.Binary != nil && source.Git == nil && source.SourceSecret != nil
+}
+
+// hasWebHookTrigger returns true if any of the triggers is a webhook trigger.
+func hasWebHookTrigger(triggers []buildapi.BuildTriggerPolicy) bool {
+	for _, trigger := range triggers {
+		if trigger.Type == buildapi.GitHubWebHookBuildTriggerType || trigger.Type == buildapi.GenericWebHookBuildTriggerType {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxActiveDeadlineSeconds is the cluster-configured maximum active deadline of
+// build pods. CompletionDeadlineSeconds becomes the active deadline of the build
+// pod, so when this is positive the completion deadline may not exceed it.
+var MaxActiveDeadlineSeconds int64
+
+// RequireStableStrategyType, when true, makes ValidateBuildConfigUpdate reject
+// updates that change the strategy type of a BuildConfig.
+var RequireStableStrategyType bool
+
 func ValidateBuildConfigUpdate(config *buildapi.BuildConfig, older *buildapi.BuildConfig) fielderrors.ValidationErrorList {
 	allErrs := fielderrors.ValidationErrorList{}
 	allErrs = append(allErrs, validation.ValidateObjectMetaUpdate(&config.ObjectMeta, &older.ObjectMeta).Prefix("metadata")...)
 
+	if RequireStableStrategyType && config.Spec.Strategy.Type != older.Spec.Strategy.Type {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("spec.strategy.type", config.Spec.Strategy.Type, fmt.Sprintf("may not be changed from %s", older.Spec.Strategy.Type)))
+	}
+
 	allErrs = append(allErrs, ValidateBuildConfig(config)...)
 	return allErrs
 }
@@ -112,6 +727,17 @@ func ValidateBuildRequest(request *buildapi.BuildRequest) fielderrors.Validation
 	return allErrs
 }
 
+// ValidateBuildSpecBytes decodes data as a JSON BuildSpec and validates it. A
+// decoding failure is returned as an error rather than as a validation error,
+// so callers feeding arbitrary input can tell the two apart.
+func ValidateBuildSpecBytes(data []byte) (fielderrors.ValidationErrorList, error) {
+	spec := &buildapi.BuildSpec{}
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, err
+	}
+	return validateBuildSpec(spec), nil
+}
+
 func validateBuildSpec(spec *buildapi.BuildSpec) fielderrors.ValidationErrorList {
 	allErrs := fielderrors.ValidationErrorList{}
 	hasSourceType := len(spec.Source.Type) != 0
@@ -122,21 +748,29 @@ func validateBuildSpec(spec *buildapi.BuildSpec) fielderrors.ValidationErrorList
 	case t == buildapi.SourceBuildStrategyType:
 		allErrs = append(allErrs, validateSource(&spec.Source).Prefix("source")...)
 		if spec.Source.Type == buildapi.BuildSourceDockerfile {
-			allErrs = append(allErrs, fielderrors.NewFieldInvalid("source.type", nil, "may not be type Dockerfile for source builds"))
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("source.type", spec.Source.Type, fmt.Sprintf("a %s strategy build may not use a %s source, use the %s strategy to build a Dockerfile", t, spec.Source.Type, buildapi.DockerBuildStrategyType)))
 		}
 	case t == buildapi.DockerBuildStrategyType:
 		allErrs = append(allErrs, validateSource(&spec.Source).Prefix("source")...)
 	}
 	if spec.Revision != nil {
 		allErrs = append(allErrs, validateRevision(spec.Revision).Prefix("revision")...)
+		if expected := sourceRevisionType(&spec.Source); len(expected) != 0 && len(spec.Revision.Type) != 0 && spec.Revision.Type != expected {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("revision.type", spec.Revision.Type, fmt.Sprintf("must be %s to match the source", expected)))
+		}
 	}
 	if spec.CompletionDeadlineSeconds != nil {
 		if *spec.CompletionDeadlineSeconds <= 0 {
 			allErrs = append(allErrs, fielderrors.NewFieldInvalid("completionDeadlineSeconds", spec.CompletionDeadlineSeconds, "completionDeadlineSeconds must be a positive integer greater than 0"))
+		} else if MaxActiveDeadlineSeconds > 0 && *spec.CompletionDeadlineSeconds > MaxActiveDeadlineSeconds {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("completionDeadlineSeconds", spec.CompletionDeadlineSeconds, fmt.Sprintf("completionDeadlineSeconds must not exceed the maximum active deadline of %d seconds", MaxActiveDeadlineSeconds)))
 		}
 	}
 
 	allErrs = append(allErrs, validateOutput(&spec.Output).Prefix("output")...)
+	if RejectMissingOutput && isMissingOutput(spec) {
+		allErrs = append(allErrs, fielderrors.NewFieldRequired("output.to"))
+	}
 	allErrs = append(allErrs, validateStrategy(&spec.Strategy).Prefix("strategy")...)
 
 	// TODO: validate resource requirements (prereq: https://github.com/kubernetes/kubernetes/pull/7059)
@@ -145,6 +779,18 @@ func validateBuildSpec(spec *buildapi.BuildSpec) fielderrors.ValidationErrorList
 
 const maxDockerfileLengthBytes = 60 * 1000
 
+// isValidGitRef returns true if ref could name a branch, tag or commit. This
+// follows the rules of git check-ref-format that apply to a single ref name.
+func isValidGitRef(ref string) bool {
+	if strings.HasPrefix(ref, "-") || strings.HasPrefix(ref, "/") || strings.HasSuffix(ref, "/") || strings.HasSuffix(ref, ".") || strings.HasSuffix(ref, ".lock") {
+		return false
+	}
+	if strings.Contains(ref, "..") || strings.Contains(ref, "//") || strings.Contains(ref, "@{") || strings.Contains(ref, "/.") {
+		return false
+	}
+	return !strings.ContainsAny(ref, " \t\n~^:?*[\\\x7f")
+}
+
 func hasProxy(source *buildapi.GitBuildSource) bool {
 	return len(source.HTTPProxy) > 0 || len(source.HTTPSProxy) > 0
 }
@@ -161,9 +807,6 @@ func validateSource(input *buildapi.BuildSource) fielderrors.ValidationErrorList
 		if input.Dockerfile != nil {
 			allErrs = append(allErrs, validateDockerfile(*input.Dockerfile)...)
 		}
-		if input.Binary != nil {
-			allErrs = append(allErrs, fielderrors.NewFieldInvalid("binary", "", "may not be set when type is Git"))
-		}
 	case buildapi.BuildSourceBinary:
 		if input.Binary == nil {
 			allErrs = append(allErrs, fielderrors.NewFieldRequired("binary"))
@@ -173,9 +816,6 @@ func validateSource(input *buildapi.BuildSource) fielderrors.ValidationErrorList
 		if input.Dockerfile != nil {
 			allErrs = append(allErrs, validateDockerfile(*input.Dockerfile)...)
 		}
-		if input.Git != nil {
-			allErrs = append(allErrs, fielderrors.NewFieldInvalid("git", "", "may not be set when type is Binary"))
-		}
 	case buildapi.BuildSourceDockerfile:
 		if input.Dockerfile == nil {
 			allErrs = append(allErrs, fielderrors.NewFieldRequired("dockerfile"))
@@ -196,42 +836,181 @@ func validateSource(input *buildapi.BuildSource) fielderrors.ValidationErrorList
 	default:
 		allErrs = append(allErrs, fielderrors.NewFieldInvalid("type", input.Type, fmt.Sprintf("source type must be one of Git, Dockerfile, or Binary")))
 	}
-	allErrs = append(allErrs, validateSecretRef(input.SourceSecret).Prefix("sourceSecret")...)
+	allErrs = append(allErrs, validateSourceFields(input)...)
+	allErrs = append(allErrs, validateSecretRef(input.SourceSecret, sourceSecretKind(input)).Prefix("sourceSecret")...)
+	if RejectBinarySourceSecret && isBinaryWithSourceSecret(input) {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("sourceSecret", input.SourceSecret.Name, "may not be set for a binary build, which has no remote repository to authenticate to"))
+	}
+
+	// an inline Dockerfile is written to the same place as the binary would be
+	if input.Binary != nil && input.Dockerfile != nil && input.Binary.AsFile == "Dockerfile" {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("binary.asFile", input.Binary.AsFile, "may not be 'Dockerfile' when an inline dockerfile is also provided"))
+	}
 
 	if len(input.ContextDir) != 0 {
-		cleaned := path.Clean(input.ContextDir)
-		if strings.HasPrefix(cleaned, "..") {
-			allErrs = append(allErrs, fielderrors.NewFieldInvalid("contextDir", input.ContextDir, "context dir must not be a relative path"))
+		if cleaned, err := NormalizeContextDir(input.ContextDir); err != nil {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("contextDir", input.ContextDir, err.Error()))
 		} else {
-			if cleaned == "." {
-				cleaned = ""
-			}
 			input.ContextDir = cleaned
 		}
 	}
 
+	// a binary provided as a single file is the only content of the build input,
+	// so there is no directory within it to use as the context.
+	if input.Binary != nil && len(input.Binary.AsFile) != 0 && len(input.ContextDir) != 0 {
+		if cleaned, err := NormalizeContextDir(input.ContextDir); err == nil && len(cleaned) != 0 {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("contextDir", input.ContextDir, "may not be set when binary.asFile is set, the build input only contains that file"))
+		}
+	}
+
 	return allErrs
 }
 
+// allowedSourceFields lists the source fields that may be set for each source
+// type. A Dockerfile source may be combined with either git or binary, which is
+// checked separately.
+var allowedSourceFields = map[buildapi.BuildSourceType]sets.String{
+	buildapi.BuildSourceGit:        sets.NewString("git", "dockerfile"),
+	buildapi.BuildSourceBinary:     sets.NewString("binary", "dockerfile"),
+	buildapi.BuildSourceDockerfile: sets.NewString("dockerfile", "git", "binary"),
+}
+
+// validateSourceFields rejects any source field that doesn't belong to the
+// declared source type.
+func validateSourceFields(input *buildapi.BuildSource) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	allowed, ok := allowedSourceFields[input.Type]
+	if !ok {
+		return allErrs
+	}
+	populated := []string{}
+	if input.Git != nil {
+		populated = append(populated, "git")
+	}
+	if input.Binary != nil {
+		populated = append(populated, "binary")
+	}
+	if input.Dockerfile != nil {
+		populated = append(populated, "dockerfile")
+	}
+	for _, field := range populated {
+		if !allowed.Has(field) {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid(field, "", fmt.Sprintf("may not be set when type is %s", input.Type)))
+		}
+	}
+	return allErrs
+}
+
+// NormalizeContextDir returns the cleaned form of a source context dir, with
+// "." normalized to the empty string. An error is returned if the dir is
+// absolute or escapes the root of the source.
+func NormalizeContextDir(dir string) (string, error) {
+	if len(dir) == 0 {
+		return "", nil
+	}
+	if path.IsAbs(dir) {
+		return "", fmt.Errorf("context dir must not be an absolute path")
+	}
+	cleaned := path.Clean(dir)
+	if strings.HasPrefix(cleaned, "..") {
+		return "", fmt.Errorf("context dir must not be a relative path")
+	}
+	if cleaned == "." {
+		cleaned = ""
+	}
+	return cleaned, nil
+}
+
 func validateDockerfile(dockerfile string) fielderrors.ValidationErrorList {
 	allErrs := fielderrors.ValidationErrorList{}
 	if len(dockerfile) > maxDockerfileLengthBytes {
 		allErrs = append(allErrs, fielderrors.NewFieldInvalid("dockerfile", "", fmt.Sprintf("must be smaller than %d bytes", maxDockerfileLengthBytes)))
+		return allErrs
+	}
+	if DockerfilePolicy != nil {
+		node, err := parser.Parse(strings.NewReader(dockerfile))
+		if err != nil {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("dockerfile", "", fmt.Sprintf("could not be parsed: %v", err)))
+		} else if err := DockerfilePolicy(node); err != nil {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("dockerfile", "", err.Error()))
+		}
 	}
 	return allErrs
 }
 
-func validateSecretRef(ref *kapi.LocalObjectReference) fielderrors.ValidationErrorList {
+// DockerfilePolicy is consulted for every inline Dockerfile with its parsed
+// instructions, and may reject the Dockerfile by returning an error. It is nil
+// by default, which accepts any content.
+var DockerfilePolicy func(node *parser.Node) error
+
+// RemoteAddDockerfilePolicy is a DockerfilePolicy rejecting ADD instructions
+// that fetch content from a remote URL.
+func RemoteAddDockerfilePolicy(node *parser.Node) error {
+	for _, child := range node.Children {
+		if child.Value != command.Add {
+			continue
+		}
+		for arg := child.Next; arg != nil; arg = arg.Next {
+			if isHTTPScheme(arg.Value) {
+				return fmt.Errorf("ADD may not fetch remote URL %q", arg.Value)
+			}
+		}
+	}
+	return nil
+}
+
+// SecretKind describes the kind of credentials a referenced secret is expected
+// to hold.
+type SecretKind string
+
+const (
+	// SecretKindAny places no expectation on the contents of the secret.
+	SecretKindAny SecretKind = ""
+	// SecretKindSSH indicates the secret is expected to hold an ssh private key.
+	SecretKindSSH SecretKind = "ssh"
+)
+
+// SecretRefPolicy is consulted for every secret reference whose expected kind
+// is known. The secret itself can't be read during validation, so a policy may
+// only inspect the reference. A returned error rejects the reference. The
+// default is nil, which accepts all references.
+var SecretRefPolicy func(ref *kapi.LocalObjectReference, kind SecretKind) error
+
+// SSHSecretNamePolicy is a SecretRefPolicy requiring that secrets expected to
+// hold an ssh key follow the naming convention of including "ssh" in the name.
+func SSHSecretNamePolicy(ref *kapi.LocalObjectReference, kind SecretKind) error {
+	if kind == SecretKindSSH && !strings.Contains(ref.Name, "ssh") {
+		return fmt.Errorf("secrets holding an ssh key must include \"ssh\" in their name")
+	}
+	return nil
+}
+
+func validateSecretRef(ref *kapi.LocalObjectReference, kind SecretKind) fielderrors.ValidationErrorList {
 	allErrs := fielderrors.ValidationErrorList{}
 	if ref == nil {
 		return allErrs
 	}
 	if len(ref.Name) == 0 {
 		allErrs = append(allErrs, fielderrors.NewFieldRequired("name"))
+		return allErrs
+	}
+	if kind != SecretKindAny && SecretRefPolicy != nil {
+		if err := SecretRefPolicy(ref, kind); err != nil {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("name", ref.Name, err.Error()))
+		}
 	}
 	return allErrs
 }
 
+// sourceSecretKind returns the kind of credentials the source secret is
+// expected to hold based on how the source is fetched.
+func sourceSecretKind(source *buildapi.BuildSource) SecretKind {
+	if source.Git != nil && isSSHGitURI(source.Git.URI) {
+		return SecretKindSSH
+	}
+	return SecretKindAny
+}
+
 func isHTTPScheme(in string) bool {
 	u, err := url.Parse(in)
 	if err != nil {
@@ -240,25 +1019,73 @@ func isHTTPScheme(in string) bool {
 	return u.Scheme == "http" || u.Scheme == "https"
 }
 
+var scpLikeGitURI = regexp.MustCompile(`^[\w.-]+@[\w.-]+:`)
+
+// isSSHGitURI returns true if the uri is cloned over ssh, either through an
+// ssh:// url or the scp-like user@host:path syntax.
+func isSSHGitURI(in string) bool {
+	if u, err := url.Parse(in); err == nil && len(u.Scheme) != 0 {
+		return u.Scheme == "ssh"
+	}
+	return scpLikeGitURI.MatchString(in)
+}
+
 func validateGitSource(git *buildapi.GitBuildSource) fielderrors.ValidationErrorList {
 	allErrs := fielderrors.ValidationErrorList{}
 	if len(git.URI) == 0 {
 		allErrs = append(allErrs, fielderrors.NewFieldRequired("uri"))
 	} else if !isValidURL(git.URI) {
-		allErrs = append(allErrs, fielderrors.NewFieldInvalid("uri", git.URI, "uri is not a valid url"))
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("uri", buildutil.SanitizeGitURI(git.URI), "uri is not a valid url"))
+	} else if u, _ := url.Parse(git.URI); len(u.Fragment) != 0 {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("uri", buildutil.SanitizeGitURI(git.URI), fmt.Sprintf("uri may not contain a fragment, set ref to %q to build that branch or tag", u.Fragment)))
+	}
+	if len(git.Ref) != 0 && !isValidGitRef(git.Ref) {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("ref", git.Ref, "must be a valid git branch, tag, or commit"))
+	}
+	if git.CloneDepth != nil && *git.CloneDepth < 1 {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("cloneDepth", *git.CloneDepth, "must be at least 1"))
 	}
 	if len(git.HTTPProxy) != 0 && !isValidURL(git.HTTPProxy) {
-		allErrs = append(allErrs, fielderrors.NewFieldInvalid("httpproxy", git.HTTPProxy, "proxy is not a valid url"))
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("httpproxy", sanitizeURL(git.HTTPProxy), "proxy is not a valid url"))
 	}
 	if len(git.HTTPSProxy) != 0 && !isValidURL(git.HTTPSProxy) {
-		allErrs = append(allErrs, fielderrors.NewFieldInvalid("httpsproxy", git.HTTPSProxy, "proxy is not a valid url"))
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("httpsproxy", sanitizeURL(git.HTTPSProxy), "proxy is not a valid url"))
 	}
 	if hasProxy(git) && !isHTTPScheme(git.URI) {
-		allErrs = append(allErrs, fielderrors.NewFieldInvalid("uri", git.URI, "only http:// and https:// GIT protocols are allowed with HTTP or HTTPS proxy set"))
+		if git.LFS && isSSHGitURI(git.URI) {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("uri", buildutil.SanitizeGitURI(git.URI), "Git LFS over ssh is not supported with HTTP or HTTPS proxy set"))
+		} else {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("uri", buildutil.SanitizeGitURI(git.URI), "only http:// and https:// GIT protocols are allowed with HTTP or HTTPS proxy set"))
+		}
 	}
 	return allErrs
 }
 
+// BinaryMode describes how the payload of a binary build is consumed.
+type BinaryMode string
+
+const (
+	// BinaryModeFile places the payload in the build input as a single file.
+	BinaryModeFile BinaryMode = "file"
+	// BinaryModeArchive extracts the payload as the source of the build.
+	BinaryModeArchive BinaryMode = "archive"
+)
+
+// BinarySourceMode returns whether a binary source expects its payload as a
+// single file or as an archive. The payload only arrives when the build is
+// started, so this is the only thing known about it up front. An empty mode is
+// returned for a nil source.
+func BinarySourceMode(source *buildapi.BinaryBuildSource) BinaryMode {
+	switch {
+	case source == nil:
+		return ""
+	case len(source.AsFile) != 0:
+		return BinaryModeFile
+	default:
+		return BinaryModeArchive
+	}
+}
+
 func validateBinarySource(source *buildapi.BinaryBuildSource) fielderrors.ValidationErrorList {
 	allErrs := fielderrors.ValidationErrorList{}
 	if len(source.AsFile) != 0 {
@@ -277,17 +1104,102 @@ func validateRevision(revision *buildapi.SourceRevision) fielderrors.ValidationE
 	if len(revision.Type) == 0 {
 		allErrs = append(allErrs, fielderrors.NewFieldRequired("type"))
 	}
+	if revision.Git != nil {
+		allErrs = append(allErrs, validateSourceControlUser(&revision.Git.Author).Prefix("git.author")...)
+		allErrs = append(allErrs, validateSourceControlUser(&revision.Git.Committer).Prefix("git.committer")...)
+	}
 	// TODO: validate other stuff
 	return allErrs
 }
 
+// validateSourceControlUser checks that the email of the user, when set, can be
+// written into a git author or committer line. Git accepts any address a user
+// has configured, such as one without a domain, and these are copied into
+// binary build revisions from the local git config, so the address is not
+// required to be well formed otherwise.
+func validateSourceControlUser(user *buildapi.SourceControlUser) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	if len(user.Email) == 0 {
+		return allErrs
+	}
+	if len(strings.TrimSpace(user.Email)) == 0 {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("email", user.Email, "may not be blank"))
+	} else if strings.ContainsAny(user.Email, "<>\n") {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("email", user.Email, "may not contain '<', '>' or a newline"))
+	}
+	return allErrs
+}
+
+// sourceRevisionType returns the type of revision the source is built from, or
+// an empty string if any revision type may be recorded. Binary builds may
+// record the commit of the uploaded content, so only a source cloned from git
+// requires a particular revision type.
+func sourceRevisionType(source *buildapi.BuildSource) buildapi.BuildSourceType {
+	if source.Git != nil {
+		return buildapi.BuildSourceGit
+	}
+	return ""
+}
+
+// isGitRevision returns true if the revision describes a git commit.
+func isGitRevision(revision *buildapi.SourceRevision) bool {
+	return revision != nil && (revision.Type == buildapi.BuildSourceGit || revision.Git != nil)
+}
+
+// ValidateObjectReferenceKind tests that the kind of the reference is one of
+// the allowed kinds.
+func ValidateObjectReferenceKind(ref *kapi.ObjectReference, allowed []string) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	if len(ref.Kind) == 0 {
+		allErrs = append(allErrs, fielderrors.NewFieldRequired("kind"))
+		return allErrs
+	}
+	for _, kind := range allowed {
+		if ref.Kind == kind {
+			return allErrs
+		}
+	}
+	allErrs = append(allErrs, fielderrors.NewFieldInvalid("kind", ref.Kind, "must be "+quotedList(allowed)))
+	return allErrs
+}
+
+// quotedList returns the items quoted and joined into a list of alternatives,
+// such as 'a', 'b', or 'c'.
+func quotedList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = "'" + item + "'"
+	}
+	switch len(quoted) {
+	case 0:
+		return ""
+	case 1:
+		return quoted[0]
+	case 2:
+		return quoted[0] + " or " + quoted[1]
+	default:
+		return strings.Join(quoted[:len(quoted)-1], ", ") + ", or " + quoted[len(quoted)-1]
+	}
+}
+
+// toImageReferenceKinds are the kinds a build may push its output to.
+var toImageReferenceKinds = []string{"ImageStreamTag", "DockerImage"}
+
+// fromImageReferenceKinds are the kinds a builder image may be pulled from.
+var fromImageReferenceKinds = []string{"ImageStreamTag", "ImageStreamImage", "DockerImage"}
+
 func validateToImageReference(reference *kapi.ObjectReference) fielderrors.ValidationErrorList {
+	if errs := ValidateObjectReferenceKind(reference, toImageReferenceKinds); len(errs) != 0 {
+		return errs
+	}
 	allErrs := fielderrors.ValidationErrorList{}
 	kind, name, namespace := reference.Kind, reference.Name, reference.Namespace
 	switch kind {
 	case "ImageStreamTag":
 		if len(name) == 0 {
 			allErrs = append(allErrs, fielderrors.NewFieldRequired("name"))
+		} else if strings.Contains(name, "/") {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("name", name, "ImageStreamTag names may not contain '/', use the namespace field to refer to another namespace"))
 		} else if _, _, ok := imageapi.SplitImageStreamTag(name); !ok {
 			allErrs = append(allErrs, fielderrors.NewFieldInvalid("name", name, "ImageStreamTag object references must be in the form <name>:<tag>"))
 		}
@@ -302,16 +1214,22 @@ func validateToImageReference(reference *kapi.ObjectReference) fielderrors.Valid
 		if _, err := imageapi.ParseDockerImageReference(name); err != nil {
 			allErrs = append(allErrs, fielderrors.NewFieldInvalid("name", name, fmt.Sprintf("name is not a valid Docker pull specification: %v", err)))
 		}
-	case "":
-		allErrs = append(allErrs, fielderrors.NewFieldRequired("kind"))
-	default:
-		allErrs = append(allErrs, fielderrors.NewFieldInvalid("kind", kind, "the target of build output must be an 'ImageStreamTag' or 'DockerImage'"))
-
+	}
+	if RequireImmutableOutputTag && len(allErrs) == 0 && isFloatingTag(reference) {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("name", name, fmt.Sprintf("the %q tag is moved by every push, reproducible builds must push to an immutable tag", imageapi.DefaultImageTag)))
 	}
 	return allErrs
 }
 
+// RequireImmutableOutputTag makes validation reject outputs pushed to the
+// floating default tag, for clusters that require reproducible builds whose
+// images can be referred to by tag.
+var RequireImmutableOutputTag bool
+
 func validateFromImageReference(reference *kapi.ObjectReference) fielderrors.ValidationErrorList {
+	if errs := ValidateObjectReferenceKind(reference, fromImageReferenceKinds); len(errs) != 0 {
+		return errs
+	}
 	allErrs := fielderrors.ValidationErrorList{}
 	kind, name, namespace := reference.Kind, reference.Name, reference.Namespace
 	switch kind {
@@ -320,6 +1238,10 @@ func validateFromImageReference(reference *kapi.ObjectReference) fielderrors.Val
 			allErrs = append(allErrs, fielderrors.NewFieldRequired("name"))
 		} else if _, _, ok := imageapi.SplitImageStreamTag(name); !ok {
 			allErrs = append(allErrs, fielderrors.NewFieldInvalid("name", name, "ImageStreamTag object references must be in the form <name>:<tag>"))
+		} else if strings.HasSuffix(name, ":") {
+			// SplitImageStreamTag defaults an empty tag to latest, which would
+			// hide that the tag was left out
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("name", name, "the tag of an ImageStreamTag may not be empty, use <name>:<tag>"))
 		}
 
 		if len(namespace) != 0 && !kvalidation.IsDNS1123Subdomain(namespace) {
@@ -342,11 +1264,6 @@ func validateFromImageReference(reference *kapi.ObjectReference) fielderrors.Val
 		if len(namespace) != 0 && !kvalidation.IsDNS1123Subdomain(namespace) {
 			allErrs = append(allErrs, fielderrors.NewFieldInvalid("namespace", namespace, "namespace must be a valid subdomain"))
 		}
-	case "":
-		allErrs = append(allErrs, fielderrors.NewFieldRequired("kind"))
-	default:
-		allErrs = append(allErrs, fielderrors.NewFieldInvalid("kind", kind, "the source of a builder image must be an 'ImageStreamTag', 'ImageStreamImage', or 'DockerImage'"))
-
 	}
 	return allErrs
 }
@@ -359,11 +1276,22 @@ func validateOutput(output *buildapi.BuildOutput) fielderrors.ValidationErrorLis
 		allErrs = append(allErrs, validateToImageReference(output.To).Prefix("to")...)
 	}
 
-	allErrs = append(allErrs, validateSecretRef(output.PushSecret).Prefix("pushSecret")...)
+	allErrs = append(allErrs, validateSecretRef(output.PushSecret, SecretKindAny).Prefix("pushSecret")...)
+	if output.PushSecret != nil && len(output.PushSecret.Name) != 0 && PushSecretPolicy != nil {
+		if err := PushSecretPolicy(output.PushSecret.Name); err != nil {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("pushSecret.name", output.PushSecret.Name, err.Error()))
+		}
+	}
 
 	return allErrs
 }
 
+// PushSecretPolicy is consulted with the name of every push secret. Like
+// SecretRefPolicy it can't read the secret, so it may only enforce a naming
+// convention, and a returned error rejects the secret. The default is nil,
+// which accepts all names.
+var PushSecretPolicy func(name string) error
+
 func validateStrategy(strategy *buildapi.BuildStrategy) fielderrors.ValidationErrorList {
 	allErrs := fielderrors.ValidationErrorList{}
 
@@ -405,21 +1333,165 @@ func validateDockerStrategy(strategy *buildapi.DockerBuildStrategy) fielderrors.
 		allErrs = append(allErrs, validateFromImageReference(strategy.From).Prefix("from")...)
 	}
 
-	allErrs = append(allErrs, validateSecretRef(strategy.PullSecret).Prefix("pullSecret")...)
+	allErrs = append(allErrs, validateSecretRef(strategy.PullSecret, SecretKindAny).Prefix("pullSecret")...)
+	allErrs = append(allErrs, validateEnv(strategy.Env).Prefix("env")...)
 	return allErrs
 }
 
 func validateSourceStrategy(strategy *buildapi.SourceBuildStrategy) fielderrors.ValidationErrorList {
 	allErrs := fielderrors.ValidationErrorList{}
 	allErrs = append(allErrs, validateFromImageReference(&strategy.From).Prefix("from")...)
-	allErrs = append(allErrs, validateSecretRef(strategy.PullSecret).Prefix("pullSecret")...)
+	allErrs = append(allErrs, validateSecretRef(strategy.PullSecret, SecretKindAny).Prefix("pullSecret")...)
+	allErrs = append(allErrs, validateEnv(strategy.Env).Prefix("env")...)
+	if len(strategy.Scripts) != 0 {
+		allErrs = append(allErrs, validateScriptsURL(strategy.Scripts).Prefix("scripts")...)
+	}
+	return allErrs
+}
+
+// supportedScriptsSchemes are the url schemes the source builder can download
+// scripts from. image:// reads them from a path in the builder image and a url
+// without a scheme, or with file://, is a local path.
+var supportedScriptsSchemes = sets.NewString("", "http", "https", "image", "file")
+
+// validateScriptsURL checks that the scripts location is a url with a scheme
+// the source builder supports, or a path.
+func validateScriptsURL(scripts string) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	u, err := url.Parse(scripts)
+	if err != nil {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("", sanitizeURL(scripts), "scripts is not a valid url"))
+		return allErrs
+	}
+	if !supportedScriptsSchemes.Has(u.Scheme) {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("", sanitizeURL(scripts), fmt.Sprintf("scheme %q is not supported, use http://, https://, image:// or a path", u.Scheme)))
+	}
 	return allErrs
 }
 
 func validateCustomStrategy(strategy *buildapi.CustomBuildStrategy) fielderrors.ValidationErrorList {
 	allErrs := fielderrors.ValidationErrorList{}
 	allErrs = append(allErrs, validateFromImageReference(&strategy.From).Prefix("from")...)
-	allErrs = append(allErrs, validateSecretRef(strategy.PullSecret).Prefix("pullSecret")...)
+	allErrs = append(allErrs, validateSecretRef(strategy.PullSecret, SecretKindAny).Prefix("pullSecret")...)
+	allErrs = append(allErrs, validateEnv(strategy.Env).Prefix("env")...)
+	allErrs = append(allErrs, validateReservedEnv(strategy.Env, reservedCustomBuildEnv).Prefix("env")...)
+	for i := range strategy.Secrets {
+		allErrs = append(allErrs, validateSecretSpec(&strategy.Secrets[i]).PrefixIndex(i).Prefix("secrets")...)
+	}
+	return allErrs
+}
+
+// RequireExposeDockerSocketAcknowledgment makes validation reject custom
+// strategies exposing the docker socket unless the object is annotated with
+// ExposeDockerSocketAcknowledgedAnnotation. The socket gives the builder control
+// of the node, so hardened clusters may want the escalation to be explicit.
+var RequireExposeDockerSocketAcknowledgment bool
+
+// validateExposeDockerSocketAcknowledged enforces
+// RequireExposeDockerSocketAcknowledgment for the strategy of an object with the
+// given annotations.
+func validateExposeDockerSocketAcknowledged(strategy *buildapi.BuildStrategy, annotations map[string]string) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	if !RequireExposeDockerSocketAcknowledgment || strategy.CustomStrategy == nil || !strategy.CustomStrategy.ExposeDockerSocket {
+		return allErrs
+	}
+	if annotations[buildapi.ExposeDockerSocketAcknowledgedAnnotation] != "true" {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("spec.strategy.customStrategy.exposeDockerSocket", true, fmt.Sprintf("exposing the docker socket requires the %s annotation to be set to \"true\"", buildapi.ExposeDockerSocketAcknowledgedAnnotation)))
+	}
+	return allErrs
+}
+
+// validateSecretSpec checks that the secret is mounted at an absolute path
+// other than the root of the builder's filesystem.
+func validateSecretSpec(secret *buildapi.SecretSpec) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	switch mountPath := secret.MountPath; {
+	case len(mountPath) == 0:
+		allErrs = append(allErrs, fielderrors.NewFieldRequired("mountPath"))
+	case !path.IsAbs(mountPath):
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("mountPath", mountPath, "must be an absolute path"))
+	case path.Clean(mountPath) == "/":
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("mountPath", mountPath, "may not be the root directory"))
+	}
+	return allErrs
+}
+
+// reservedCustomBuildEnv are the env vars set on custom builder pods to
+// describe the build. User env is appended after them, so setting one of these
+// would replace the value the builder relies on.
+var reservedCustomBuildEnv = sets.NewString(
+	"BUILD",
+	"SOURCE_REPOSITORY",
+	"SOURCE_CONTEXT_DIR",
+	"SOURCE_REF",
+	"SOURCE_URI",
+	"SOURCE_SECRET_PATH",
+	"OUTPUT_REGISTRY",
+	"OUTPUT_IMAGE",
+	"PUSH_DOCKERCFG_PATH",
+	"PULL_DOCKERCFG_PATH",
+	"DOCKER_SOCKET",
+)
+
+func validateReservedEnv(vars []kapi.EnvVar, reserved sets.String) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	for i, ev := range vars {
+		if reserved.Has(ev.Name) {
+			vErrs := fielderrors.ValidationErrorList{fielderrors.NewFieldInvalid("name", ev.Name, "is reserved for use by the build")}
+			allErrs = append(allErrs, vErrs.PrefixIndex(i)...)
+		}
+	}
+	return allErrs
+}
+
+func validateEnv(vars []kapi.EnvVar) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+
+	for i, ev := range vars {
+		vErrs := fielderrors.ValidationErrorList{}
+		if len(ev.Name) == 0 {
+			vErrs = append(vErrs, fielderrors.NewFieldRequired("name"))
+		} else if !kvalidation.IsCIdentifier(ev.Name) {
+			vErrs = append(vErrs, fielderrors.NewFieldInvalid("name", ev.Name, "must match regex "+kvalidation.CIdentifierFmt))
+		}
+		vErrs = append(vErrs, validateEnvVarValueFrom(ev).Prefix("valueFrom")...)
+		allErrs = append(allErrs, vErrs.PrefixIndex(i)...)
+	}
+	return allErrs
+}
+
+// validEnvFieldPaths are the pod fields a build env var may be sourced from,
+// matching those allowed for container env vars.
+var validEnvFieldPaths = sets.NewString("metadata.name", "metadata.namespace", "status.podIP")
+
+func validateEnvVarValueFrom(ev kapi.EnvVar) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	if ev.ValueFrom == nil {
+		return allErrs
+	}
+
+	// FieldRef is currently the only kind of source
+	fieldRef := ev.ValueFrom.FieldRef
+	if fieldRef == nil {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("", "", "must specify a source"))
+		return allErrs
+	}
+	if len(ev.Value) != 0 {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("", "", "sources cannot be specified when value is not empty"))
+	}
+	switch {
+	case len(fieldRef.APIVersion) == 0:
+		allErrs = append(allErrs, fielderrors.NewFieldRequired("fieldRef.apiVersion"))
+	case len(fieldRef.FieldPath) == 0:
+		allErrs = append(allErrs, fielderrors.NewFieldRequired("fieldRef.fieldPath"))
+	default:
+		internalFieldPath, _, err := kapi.Scheme.ConvertFieldLabel(fieldRef.APIVersion, "Pod", fieldRef.FieldPath, "")
+		if err != nil {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("fieldRef.fieldPath", fieldRef.FieldPath, "error converting fieldPath"))
+		} else if !validEnvFieldPaths.Has(internalFieldPath) {
+			allErrs = append(allErrs, fielderrors.NewFieldValueNotSupported("fieldRef.fieldPath", internalFieldPath, validEnvFieldPaths.List()))
+		}
+	}
 	return allErrs
 }
 
@@ -474,7 +1546,26 @@ func validateWebHook(webHook *buildapi.WebHookTrigger) fielderrors.ValidationErr
 	if len(webHook.Secret) == 0 {
 		allErrs = append(allErrs, fielderrors.NewFieldRequired("secret"))
 	}
-	return allErrs
+	return redactWebHookSecret(allErrs, webHook.Secret)
+}
+
+// redactWebHookSecret replaces the secret in the values and details of errs.
+// Like the credentials of a Git URI, the secret of a webhook grants access to
+// start builds and must never be echoed in an error, whatever checks are added
+// to validateWebHook.
+func redactWebHookSecret(errs fielderrors.ValidationErrorList, secret string) fielderrors.ValidationErrorList {
+	if len(secret) == 0 {
+		return errs
+	}
+	for _, err := range errs {
+		if err, ok := err.(*fielderrors.ValidationError); ok {
+			if value, ok := err.BadValue.(string); ok {
+				err.BadValue = strings.Replace(value, secret, "redacted", -1)
+			}
+			err.Detail = strings.Replace(err.Detail, secret, "redacted", -1)
+		}
+	}
+	return errs
 }
 
 func isValidURL(uri string) bool {
@@ -482,6 +1573,42 @@ func isValidURL(uri string) bool {
 	return err == nil
 }
 
+// ValidatePodLogOptionsConversion tests that every BuildLogOptions field that
+// has a PodLogOptions counterpart was carried over to popts. An error means the
+// conversion is broken, not that the options are invalid.
+func ValidatePodLogOptionsConversion(opts *buildapi.BuildLogOptions, popts *kapi.PodLogOptions) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	dropped := func(field string, value interface{}) {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid(field, value, "was not preserved when converting to pod log options"))
+	}
+	if opts.Follow != popts.Follow {
+		dropped("follow", opts.Follow)
+	}
+	if opts.Timestamps != popts.Timestamps {
+		dropped("timestamps", opts.Timestamps)
+	}
+	if !equalInt64Ptr(opts.SinceSeconds, popts.SinceSeconds) {
+		dropped("sinceSeconds", opts.SinceSeconds)
+	}
+	if !equalInt64Ptr(opts.TailLines, popts.TailLines) {
+		dropped("tailLines", opts.TailLines)
+	}
+	if !equalInt64Ptr(opts.LimitBytes, popts.LimitBytes) {
+		dropped("limitBytes", opts.LimitBytes)
+	}
+	if (opts.SinceTime == nil) != (popts.SinceTime == nil) || (opts.SinceTime != nil && !opts.SinceTime.Equal(*popts.SinceTime)) {
+		dropped("sinceTime", opts.SinceTime)
+	}
+	return allErrs
+}
+
+func equalInt64Ptr(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 func ValidateBuildLogOptions(opts *buildapi.BuildLogOptions) fielderrors.ValidationErrorList {
 	allErrs := fielderrors.ValidationErrorList{}
 
@@ -490,6 +1617,7 @@ func ValidateBuildLogOptions(opts *buildapi.BuildLogOptions) fielderrors.Validat
 	if errs := validation.ValidatePodLogOptions(popts); len(errs) > 0 {
 		allErrs = append(allErrs, errs...)
 	}
+	allErrs = append(allErrs, ValidatePodLogOptionsConversion(opts, popts)...)
 
 	if opts.Version != nil && *opts.Version <= 0 {
 		allErrs = append(allErrs, fielderrors.NewFieldInvalid("version", *opts.Version, "build version must be greater than 0"))
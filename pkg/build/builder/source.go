@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -168,9 +169,16 @@ func extractGitSource(git git.Git, gitSource *api.GitBuildSource, revision *api.
 		return true, err
 	}
 
-	glog.V(2).Infof("Cloning source from %s", gitSource.URI)
-	if err := git.Clone(gitSource.URI, dir, s2iapi.CloneConfig{Recursive: true, Quiet: true}); err != nil {
-		return true, err
+	if gitSource.CloneDepth != nil {
+		glog.V(2).Infof("Cloning the latest %d commits of source from %s", *gitSource.CloneDepth, gitSource.URI)
+		if err := shallowClone(gitSource.URI, dir, *gitSource.CloneDepth); err != nil {
+			return true, err
+		}
+	} else {
+		glog.V(2).Infof("Cloning source from %s", gitSource.URI)
+		if err := git.Clone(gitSource.URI, dir, s2iapi.CloneConfig{Recursive: true, Quiet: true}); err != nil {
+			return true, err
+		}
 	}
 
 	// if we specify a commit, ref, or branch to checkout, do so
@@ -183,5 +191,37 @@ func extractGitSource(git git.Git, gitSource *api.GitBuildSource, revision *api.
 			return true, err
 		}
 	}
+
+	if gitSource.LFS {
+		glog.V(2).Infof("Fetching Git LFS objects from %s", gitSource.URI)
+		if err := fetchLFSObjects(dir); err != nil {
+			return true, err
+		}
+	}
 	return true, nil
 }
+
+// shallowClone clones the latest depth commits of every branch of the
+// repository at uri into dir, so that any branch or tag can be checked out
+// afterwards. The s2i git client cannot limit the depth of a clone, so git is
+// run directly.
+func shallowClone(uri, dir string, depth int) error {
+	cmd := exec.Command("git", "clone", "--quiet", "--recursive", "--no-single-branch", "--depth", strconv.Itoa(depth), uri, dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		glog.Errorf("Clone failed: source %s, target %s, with output %s", uri, dir, out)
+		return err
+	}
+	return nil
+}
+
+// fetchLFSObjects downloads the Git Large File Storage objects of the commit
+// checked out in the repository in dir. The s2i git client does not know about
+// LFS, so the git-lfs extension is run directly.
+func fetchLFSObjects(dir string) error {
+	cmd := exec.Command("git", "lfs", "pull")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("unable to fetch Git LFS objects, git-lfs is required: %v\n%s", err, out)
+	}
+	return nil
+}
@@ -2,8 +2,12 @@ package builder
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -38,3 +42,71 @@ func TestCheckRemoteGit(t *testing.T) {
 		t.Errorf("unexpected error %q", err)
 	}
 }
+
+// newGitRepository creates a git repository with the given number of commits
+// in a temporary directory, which the caller must remove.
+func newGitRepository(t *testing.T, commits int) string {
+	dir, err := ioutil.TempDir("", "source-test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			os.RemoveAll(dir)
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git("init", "--quiet")
+	for i := 0; i < commits; i++ {
+		git("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--allow-empty", "-m", fmt.Sprintf("commit %d", i))
+	}
+	return dir
+}
+
+func TestFetchLFSObjects(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := newGitRepository(t, 1)
+	defer os.RemoveAll(dir)
+
+	err := fetchLFSObjects(dir)
+	if _, lookErr := exec.LookPath("git-lfs"); lookErr != nil {
+		if err == nil || !strings.Contains(err.Error(), "git-lfs is required") {
+			t.Errorf("expected an error about the missing git-lfs, got %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestShallowClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	source := newGitRepository(t, 3)
+	defer os.RemoveAll(source)
+	target, err := ioutil.TempDir("", "source-test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(target)
+
+	// depth is ignored when cloning a local path, but not a file:// url
+	if err := shallowClone("file://"+source, filepath.Join(target, "clone"), 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cmd := exec.Command("git", "rev-list", "--count", "HEAD")
+	cmd.Dir = filepath.Join(target, "clone")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count := strings.TrimSpace(string(out)); count != "2" {
+		t.Errorf("expected 2 commits in the clone, got %s", count)
+	}
+}
@@ -66,7 +66,7 @@ func (c *ImageChangeController) HandleImageRepo(repo *imageapi.ImageStream) erro
 		// invoke a build using that image id. A new build is triggered only if the latest tagged image id or pull spec
 		// differs from the last triggered build recorded on the build config for that trigger
 		for _, trigger := range config.Spec.Triggers {
-			if trigger.Type != buildapi.ImageChangeBuildTriggerType {
+			if trigger.Type != buildapi.ImageChangeBuildTriggerType || trigger.ImageChange.Paused {
 				continue
 			}
 			if trigger.ImageChange.From != nil {
@@ -204,6 +204,28 @@ func TestBuildConfigWithDifferentTriggerType(t *testing.T) {
 	}
 }
 
+func TestPausedImageChangeTrigger(t *testing.T) {
+	// the image changed, but the trigger is paused so no build should be triggered
+	buildcfg := mockBuildConfig("registry.com/namespace/imagename", "registry.com/namespace/imagename", "testImageStream", "testTag")
+	buildcfg.Spec.Triggers[0].ImageChange.Paused = true
+	imageStream := mockImageStream("testImageStream", "registry.com/namespace/imagename", map[string]string{"testTag": "newImageID123"})
+	image := mockImage("testImage@id", "registry.com/namespace/imagename:newImageID123")
+	controller := mockImageChangeController(buildcfg, imageStream, image)
+	bcInstantiator := controller.BuildConfigInstantiator.(*buildConfigInstantiator)
+	bcUpdater := bcInstantiator.buildConfigUpdater
+
+	err := controller.HandleImageRepo(imageStream)
+	if err != nil {
+		t.Errorf("Unexpected error %v from HandleImageRepo", err)
+	}
+	if len(bcInstantiator.name) != 0 {
+		t.Error("New build generated when the trigger is paused!")
+	}
+	if bcUpdater.buildcfg != nil {
+		t.Error("BuildConfig was updated when the trigger is paused!")
+	}
+}
+
 func TestNoImageIDChange(t *testing.T) {
 	// this buildConfig has up to date configuration, but is checked eg. during
 	// startup when we're checking all the imageRepos
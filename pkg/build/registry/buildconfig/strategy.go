@@ -40,6 +40,7 @@ func (strategy) AllowUnconditionalUpdate() bool {
 func (strategy) PrepareForCreate(obj runtime.Object) {
 	bc := obj.(*api.BuildConfig)
 	dropUnknownTriggers(bc)
+	clearLastTriggeredImageIDs(bc)
 }
 
 // PrepareForUpdate clears fields that are not allowed to be set by end users on update.
@@ -88,3 +89,15 @@ func dropUnknownTriggers(bc *api.BuildConfig) {
 	}
 	bc.Spec.Triggers = triggers
 }
+
+// clearLastTriggeredImageIDs clears the image IDs recorded by the image change
+// controller, which only starts a build for an image that differs from the
+// recorded one. A config created from an export carries the ID of its source,
+// which could keep the trigger of the new config from firing.
+func clearLastTriggeredImageIDs(bc *api.BuildConfig) {
+	for i := range bc.Spec.Triggers {
+		if trigger := &bc.Spec.Triggers[i]; trigger.ImageChange != nil {
+			trigger.ImageChange.LastTriggeredImageID = ""
+		}
+	}
+}
@@ -66,3 +66,21 @@ func TestBuildConfigStrategy(t *testing.T) {
 		t.Errorf("Expected error validating")
 	}
 }
+
+func TestBuildConfigStrategyPrepareForCreateClearsLastTriggeredImageID(t *testing.T) {
+	buildConfig := &buildapi.BuildConfig{
+		ObjectMeta: kapi.ObjectMeta{Name: "config-id", Namespace: "namespace"},
+		Spec: buildapi.BuildConfigSpec{
+			Triggers: []buildapi.BuildTriggerPolicy{
+				{
+					Type:        buildapi.ImageChangeBuildTriggerType,
+					ImageChange: &buildapi.ImageChangeTrigger{LastTriggeredImageID: "centos/ruby-22-centos7:latest"},
+				},
+			},
+		},
+	}
+	Strategy.PrepareForCreate(buildConfig)
+	if id := buildConfig.Spec.Triggers[0].ImageChange.LastTriggeredImageID; len(id) != 0 {
+		t.Errorf("expected lastTriggeredImageID to be cleared, got %q", id)
+	}
+}
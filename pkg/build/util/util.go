@@ -2,9 +2,12 @@ package util
 
 import (
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 
 	kapi "k8s.io/kubernetes/pkg/api"
+	kvalidation "k8s.io/kubernetes/pkg/util/validation"
 
 	buildapi "github.com/openshift/origin/pkg/build/api"
 )
@@ -74,8 +77,88 @@ func IsBuildComplete(build *buildapi.Build) bool {
 	return build.Status.Phase != buildapi.BuildPhaseRunning && build.Status.Phase != buildapi.BuildPhasePending && build.Status.Phase != buildapi.BuildPhaseNew
 }
 
+// IsImageChangeTriggerResolved returns whether the provided trigger is an
+// ImageChange trigger that has already been resolved to an image, that is, its
+// LastTriggeredImageID is populated.
+func IsImageChangeTriggerResolved(trigger *buildapi.BuildTriggerPolicy) bool {
+	return trigger.Type == buildapi.ImageChangeBuildTriggerType && trigger.ImageChange != nil && len(trigger.ImageChange.LastTriggeredImageID) > 0
+}
+
+// WebHookURLPath returns the canonical path of the webhook served for the
+// trigger, in the form /buildconfigs/<name>/webhooks/<secret>/<type>. An error
+// is returned if the trigger is not a webhook trigger or has no secret.
+func WebHookURLPath(config *buildapi.BuildConfig, trigger *buildapi.BuildTriggerPolicy) (string, error) {
+	var hook *buildapi.WebHookTrigger
+	var hookType string
+	switch trigger.Type {
+	case buildapi.GitHubWebHookBuildTriggerType:
+		hook, hookType = trigger.GitHubWebHook, "github"
+	case buildapi.GenericWebHookBuildTriggerType:
+		hook, hookType = trigger.GenericWebHook, "generic"
+	default:
+		return "", fmt.Errorf("trigger of type %q is not a webhook trigger", trigger.Type)
+	}
+	if hook == nil || len(hook.Secret) == 0 {
+		return "", fmt.Errorf("%s webhook trigger of build config %q has no secret", trigger.Type, config.Name)
+	}
+	return fmt.Sprintf("/buildconfigs/%s/webhooks/%s/%s", config.Name, hook.Secret, hookType), nil
+}
+
+// SanitizeGitURI returns the uri with any credentials and query removed from
+// http(s) URLs, so it can be logged or reported safely. The passwords of other
+// URLs are removed as well. URIs that do not parse as URLs, such as scp-style
+// user@host:path, cannot embed credentials and are returned unchanged.
+func SanitizeGitURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || len(u.Scheme) == 0 {
+		return uri
+	}
+	switch u.Scheme {
+	case "http", "https":
+		u.User = nil
+		u.RawQuery = ""
+	default:
+		if u.User != nil {
+			if _, hasPassword := u.User.Password(); hasPassword {
+				u.User = url.User(u.User.Username())
+			}
+		}
+	}
+	return u.String()
+}
+
 // BuildNameForConfigVersion returns the name of the version-th build
 // for the config that has the provided name
 func BuildNameForConfigVersion(name string, version int) string {
 	return fmt.Sprintf("%s-%d", name, version)
 }
+
+// ComputeBuildName returns the name of the n-th build of the build config with
+// the given name, as named by BuildNameForConfigVersion. An error is returned if
+// the name is longer than a DNS label, which the build pod name and labels must
+// fit in.
+func ComputeBuildName(bcName string, n int64) (string, error) {
+	if n < 1 {
+		return "", fmt.Errorf("build number %d must be at least 1", n)
+	}
+	name := BuildNameForConfigVersion(bcName, int(n))
+	if len(name) > kvalidation.DNS1123LabelMaxLength {
+		return "", fmt.Errorf("build name %q is longer than %d characters", name, kvalidation.DNS1123LabelMaxLength)
+	}
+	return name, nil
+}
+
+// ParseBuildName splits the name of a build created from a build config into
+// the name of the config and the build number. ok is false if the name is not
+// in the form <name>-<n>.
+func ParseBuildName(name string) (bc string, n int64, ok bool) {
+	i := strings.LastIndex(name, "-")
+	if i < 1 {
+		return "", 0, false
+	}
+	n, err := strconv.ParseInt(name[i+1:], 10, 64)
+	if err != nil || n < 1 {
+		return "", 0, false
+	}
+	return name[:i], n, true
+}
@@ -51,39 +51,62 @@ func (p *WebHookPlugin) Extract(buildCfg *api.BuildConfig, secret, path string,
 		if len(body) == 0 {
 			return nil, true, nil
 		}
-		var data api.GenericWebHookEvent
-		if err = json.Unmarshal(body, &data); err != nil {
-			glog.V(4).Infof("Error unmarshaling json %v, but continuing", err)
+		refs, err := ValidateGenericWebHookPayload(body)
+		if err != nil {
+			glog.V(4).Infof("Ignoring the generic webhook payload for BuildConfig %s/%s, but continuing: %v", buildCfg.Namespace, buildCfg.Name, err)
 			return nil, true, nil
 		}
-		if data.Git == nil {
+		if refs == nil {
 			glog.V(4).Infof("No git information for the generic webhook found in %s/%s", buildCfg.Namespace, buildCfg.Name)
 			return nil, true, nil
 		}
-
-		if data.Git.Refs != nil {
-			for _, ref := range data.Git.Refs {
-				if webhook.GitRefMatches(ref.Ref, git.Ref) {
-					revision = &api.SourceRevision{
-						Type: api.BuildSourceGit,
-						Git:  &ref.GitSourceRevision,
-					}
-					return revision, true, nil
+		for i := range refs {
+			if webhook.GitRefMatches(refs[i].Ref, git.Ref) {
+				revision = &api.SourceRevision{
+					Type: api.BuildSourceGit,
+					Git:  &refs[i].GitSourceRevision,
 				}
+				return revision, true, nil
 			}
-			glog.V(2).Infof("Skipping build for BuildConfig %s/%s. None of the supplied refs matched %q", buildCfg.Namespace, buildCfg, git.Ref)
-			return nil, false, nil
 		}
-		if !webhook.GitRefMatches(data.Git.Ref, git.Ref) {
-			glog.V(2).Infof("Skipping build for BuildConfig %s/%s. Branch reference from %q does not match configuration", buildCfg.Namespace, buildCfg.Name, data.Git.Ref)
-			return nil, false, nil
+		glog.V(2).Infof("Skipping build for BuildConfig %s/%s. None of the supplied refs matched %q", buildCfg.Namespace, buildCfg.Name, git.Ref)
+		return nil, false, nil
+	}
+	return nil, true, nil
+}
+
+// ValidateGenericWebHookPayload parses data as a generic webhook payload and
+// returns the git revisions it carries, one for each ref, or nil if the payload
+// carries no git information. A payload naming a single ref must name the uri
+// of its repository as well. The refs sent from a post-receive hook belong to
+// the repository the hook runs in, and inherit the uri of the payload if any.
+func ValidateGenericWebHookPayload(data []byte) ([]api.GitRefInfo, error) {
+	var event api.GenericWebHookEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("invalid generic webhook payload: %v", err)
+	}
+	if event.Git == nil {
+		return nil, nil
+	}
+	if len(event.Type) != 0 && event.Type != api.BuildSourceGit {
+		return nil, fmt.Errorf("payload with git information has type %q, expected %q", event.Type, api.BuildSourceGit)
+	}
+	if event.Git.Refs == nil {
+		if len(event.Git.Ref) != 0 && len(event.Git.URI) == 0 {
+			return nil, fmt.Errorf("git ref %q does not name the uri of its repository", event.Git.Ref)
+		}
+		return []api.GitRefInfo{{GitBuildSource: event.Git.GitBuildSource, GitSourceRevision: event.Git.GitSourceRevision}}, nil
+	}
+	for i := range event.Git.Refs {
+		ref := &event.Git.Refs[i]
+		if len(ref.Ref) == 0 {
+			return nil, fmt.Errorf("git refs[%d] has no ref", i)
 		}
-		revision = &api.SourceRevision{
-			Type: api.BuildSourceGit,
-			Git:  &data.Git.GitSourceRevision,
+		if len(ref.URI) == 0 {
+			ref.URI = event.Git.URI
 		}
 	}
-	return revision, true, nil
+	return event.Git.Refs, nil
 }
 
 func verifyRequest(req *http.Request) error {
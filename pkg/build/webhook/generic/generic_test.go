@@ -403,3 +403,117 @@ func TestExtractWithUnmarshalError(t *testing.T) {
 		t.Error("Expected the 'revision' return value to be nil")
 	}
 }
+
+func TestValidateGenericWebHookPayload(t *testing.T) {
+	pushGitHub, err := ioutil.ReadFile("fixtures/push-github.json")
+	if err != nil {
+		t.Fatalf("Error reading setup data: %v", err)
+	}
+	postReceive, err := ioutil.ReadFile("fixtures/post-receive-git.json")
+	if err != nil {
+		t.Fatalf("Error reading setup data: %v", err)
+	}
+	tests := map[string]struct {
+		data        []byte
+		refs        []string
+		commits     []string
+		uri         string
+		expectError bool
+	}{
+		"git payload": {
+			data:    pushGitHub,
+			refs:    []string{"refs/heads/master"},
+			commits: []string{"9bdc3a26ff933b32f3e558636b58aea86a69f051"},
+			uri:     "git://mygitserver/myrepo.git",
+		},
+		"refs payload with uri": {
+			data:    []byte(`{"type":"Git","git":{"uri":"git://mygitserver/myrepo.git","refs":[{"ref":"refs/heads/master","commit":"2602ace61490de0513dfbd7c7de949356cf9bd17"}]}}`),
+			refs:    []string{"refs/heads/master"},
+			commits: []string{"2602ace61490de0513dfbd7c7de949356cf9bd17"},
+			uri:     "git://mygitserver/myrepo.git",
+		},
+		"post-receive refs payload without uri": {
+			data:    postReceive,
+			refs:    []string{"refs/heads/master"},
+			commits: []string{"2602ace61490de0513dfbd7c7de949356cf9bd17"},
+		},
+		"payload without git information": {
+			data: []byte(`{"type":"Git"}`),
+		},
+		"ref without uri": {
+			data:        []byte(`{"type":"Git","git":{"ref":"refs/heads/master","commit":"9bdc3a26ff933b32f3e558636b58aea86a69f051"}}`),
+			expectError: true,
+		},
+		"refs entry without ref": {
+			data:        []byte(`{"type":"Git","git":{"uri":"git://mygitserver/myrepo.git","refs":[{"commit":"2602ace61490de0513dfbd7c7de949356cf9bd17"}]}}`),
+			expectError: true,
+		},
+		"unexpected type": {
+			data:        []byte(`{"type":"Dockerfile","git":{"uri":"git://mygitserver/myrepo.git"}}`),
+			expectError: true,
+		},
+		"malformed json": {
+			data:        []byte(`{"type":"Git","git":`),
+			expectError: true,
+		},
+	}
+	for desc, test := range tests {
+		refs, err := ValidateGenericWebHookPayload(test.data)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("%s: expected an error", desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", desc, err)
+			continue
+		}
+		if len(refs) != len(test.refs) {
+			t.Errorf("%s: expected %d refs, got %#v", desc, len(test.refs), refs)
+			continue
+		}
+		for i, ref := range refs {
+			if ref.Ref != test.refs[i] || ref.Commit != test.commits[i] || ref.URI != test.uri {
+				t.Errorf("%s: expected ref %q at commit %q of %q, got %#v", desc, test.refs[i], test.commits[i], test.uri, ref)
+			}
+		}
+	}
+}
+
+func TestExtractWithInvalidGitPayload(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://someurl.com", strings.NewReader(`{"type":"Git","git":{"ref":"refs/heads/master","commit":"9bdc3a26ff933b32f3e558636b58aea86a69f051"}}`))
+	req.Header.Add("Content-Type", "application/json")
+	buildConfig := &api.BuildConfig{
+		Spec: api.BuildConfigSpec{
+			Triggers: []api.BuildTriggerPolicy{
+				{
+					Type: api.GenericWebHookBuildTriggerType,
+					GenericWebHook: &api.WebHookTrigger{
+						Secret: "secret100",
+					},
+				},
+			},
+			BuildSpec: api.BuildSpec{
+				Source: api.BuildSource{
+					Type: api.BuildSourceGit,
+					Git: &api.GitBuildSource{
+						Ref: "master",
+					},
+				},
+				Strategy: mockBuildStrategy,
+			},
+		},
+	}
+	plugin := New()
+	revision, proceed, err := plugin.Extract(buildConfig, "secret100", "", req)
+	if err != nil {
+		t.Errorf("Expected to be able to trigger a build without a payload error: %v", err)
+	}
+	if !proceed {
+		t.Error("Expected 'proceed' return value to be 'true'")
+	}
+	if revision != nil {
+		t.Error("Expected the 'revision' of the invalid payload to be ignored")
+	}
+}
@@ -212,6 +212,11 @@ func RunProcess(f *clientcmd.Factory, out io.Writer, cmd *cobra.Command, args []
 			fmt.Fprintf(cmd.Out(), "error processing the template %q: %v\n", obj.Name, err)
 			continue
 		}
+		if warnings := resultObj.Annotations[api.WarningsAnnotation]; len(warnings) > 0 {
+			for _, warning := range strings.Split(warnings, "\n") {
+				fmt.Fprintf(cmd.Out(), "warning: template %q: %s\n", obj.Name, warning)
+			}
+		}
 
 		if outputFormat == "describe" {
 			if s, err := (&describe.TemplateDescriber{
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/fsouza/go-dockerclient"
 	"github.com/golang/glog"
@@ -25,6 +26,7 @@ import (
 	"github.com/openshift/origin/pkg/generate/source"
 	imageapi "github.com/openshift/origin/pkg/image/api"
 	"github.com/openshift/origin/pkg/template"
+	templateapi "github.com/openshift/origin/pkg/template/api"
 	outil "github.com/openshift/origin/pkg/util"
 	dockerfileutil "github.com/openshift/origin/pkg/util/docker/dockerfile"
 )
@@ -675,6 +677,11 @@ func (c *AppConfig) buildTemplates(components app.ComponentReferences, environme
 			err = errors.NewAggregate(errs)
 			return nil, fmt.Errorf("error processing template %s/%s: %v", c.originNamespace, tpl.Name, errs)
 		}
+		if warnings := result.Annotations[templateapi.WarningsAnnotation]; len(warnings) > 0 && c.ErrOut != nil {
+			for _, warning := range strings.Split(warnings, "\n") {
+				fmt.Fprintf(c.ErrOut, "--> WARNING: template %s/%s: %s\n", c.originNamespace, tpl.Name, warning)
+			}
+		}
 		objects = append(objects, result.Objects...)
 
 		describeGeneratedTemplate(c.Out, ref, result, c.originNamespace)
@@ -6,6 +6,10 @@ import (
 	"k8s.io/kubernetes/pkg/runtime"
 )
 
+// WarningsAnnotation is set on a processed Template to the diagnostics about
+// its objects that did not prevent it from being processed, one per line.
+const WarningsAnnotation = "openshift.io/template.warnings"
+
 // Template contains the inputs needed to produce a Config.
 type Template struct {
 	unversioned.TypeMeta
@@ -1,18 +1,57 @@
 package validation
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/rand"
 	"regexp"
 
+	kapi "k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/validation"
+	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/util/fielderrors"
+	"k8s.io/kubernetes/pkg/util/sets"
 
 	oapi "github.com/openshift/origin/pkg/api"
 	"github.com/openshift/origin/pkg/template/api"
+	"github.com/openshift/origin/pkg/template/generator"
+	"github.com/openshift/origin/pkg/util/stringreplace"
 )
 
 var parameterNameExp = regexp.MustCompile(`^[a-zA-Z0-9\_]+$`)
 
+var parameterReferenceExp = regexp.MustCompile(`\$\{([a-zA-Z0-9\_]+)\}`)
+
+// NamespacedOnly, when set, rejects templates containing objects of the
+// ClusterScopedKinds, for catalogs that only allow namespaced resources. It is
+// off by default.
+var NamespacedOnly bool
+
+// ClusterScopedKinds are the kinds rejected from templates when NamespacedOnly
+// is set.
+var ClusterScopedKinds = sets.NewString(
+	"Namespace", "Node", "PersistentVolume",
+	"Project", "ProjectRequest",
+	"ClusterPolicy", "ClusterPolicyBinding", "ClusterRole", "ClusterRoleBinding",
+	"User", "Identity", "UserIdentityMapping", "Group",
+	"OAuthClient", "OAuthClientAuthorization", "OAuthAccessToken", "OAuthAuthorizeToken",
+	"ClusterNetwork", "HostSubnet", "NetNamespace",
+)
+
+// DollarParameterName is the name of the parameter that the processor always
+// substitutes with a literal "$", so that ${DOLLAR}{NAME} produces ${NAME} in a
+// processed object instead of the value of NAME.
+const DollarParameterName = "DOLLAR"
+
+// ReservedParameterNames are names that parameters may not use, because the
+// processor substitutes its own tokens of the same form.
+var ReservedParameterNames = sets.NewString(DollarParameterName)
+
+// MaxParameterValueLength is the maximum length in bytes of a parameter value.
+// Every reference to the parameter is replaced with its value, so a large value
+// bloats both the template and the objects it produces.
+const MaxParameterValueLength = 64 * 1024
+
 // ValidateParameter tests if required fields in the Parameter are set.
 func ValidateParameter(param *api.Parameter) (allErrs fielderrors.ValidationErrorList) {
 	if len(param.Name) == 0 {
@@ -22,6 +61,21 @@ func ValidateParameter(param *api.Parameter) (allErrs fielderrors.ValidationErro
 	if !parameterNameExp.MatchString(param.Name) {
 		allErrs = append(allErrs, fielderrors.NewFieldInvalid("name", param.Name, fmt.Sprintf("does not match %v", parameterNameExp)))
 	}
+	if ReservedParameterNames.Has(param.Name) {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("name", param.Name, "is reserved for use by template processing"))
+	}
+	if len(param.Value) > MaxParameterValueLength {
+		// the value itself is too large to be useful in the error
+		allErrs = append(allErrs, fielderrors.NewFieldTooLong("value", fmt.Sprintf("%d bytes", len(param.Value)), MaxParameterValueLength))
+	}
+	if param.Generate == "expression" {
+		// generating a throwaway value is the only way the generator checks
+		// the syntax of the expression
+		expressionGenerator := generator.NewExpressionValueGenerator(rand.New(rand.NewSource(0)))
+		if _, err := expressionGenerator.GenerateValue(param.From); err != nil {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("from", param.From, err.Error()))
+		}
+	}
 	return
 }
 
@@ -50,5 +104,117 @@ func validateTemplateBody(template *api.Template) (allErrs fielderrors.Validatio
 		allErrs = append(allErrs, paramErr.PrefixIndex(i).Prefix("parameters")...)
 	}
 	allErrs = append(allErrs, validation.ValidateLabels(template.ObjectLabels, "labels")...)
+	if NamespacedOnly {
+		for i, obj := range template.Objects {
+			if kind := objectKind(obj); ClusterScopedKinds.Has(kind) {
+				allErrs = append(allErrs, fielderrors.NewFieldInvalid(fmt.Sprintf("objects[%d]", i), kind, fmt.Sprintf("%s %q is cluster-scoped, only namespaced objects are allowed", kind, objectName(obj))))
+			}
+		}
+	}
 	return
 }
+
+// objectKind returns the kind of a template object, which is read from the
+// raw JSON of objects that were not decoded.
+func objectKind(obj runtime.Object) string {
+	if unknown, ok := obj.(*runtime.Unknown); ok {
+		if len(unknown.Kind) != 0 {
+			return unknown.Kind
+		}
+		meta := runtime.TypeMeta{}
+		json.Unmarshal(unknown.RawJSON, &meta)
+		return meta.Kind
+	}
+	_, kind, err := kapi.Scheme.ObjectVersionAndKind(obj)
+	if err != nil {
+		return ""
+	}
+	return kind
+}
+
+// objectName returns the name of a template object, or an empty string when it
+// cannot be determined.
+func objectName(obj runtime.Object) string {
+	if unknown, ok := obj.(*runtime.Unknown); ok {
+		raw := struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}{}
+		json.Unmarshal(unknown.RawJSON, &raw)
+		return raw.Metadata.Name
+	}
+	meta, err := kapi.ObjectMetaFor(obj)
+	if err != nil {
+		return ""
+	}
+	return meta.Name
+}
+
+// ValidateTemplateParameterUsage tests that every ${PARAMETER_NAME} expression
+// in the Template objects refers to a declared Parameter. The Template is not
+// processed, so this may be used to lint templates offline.
+func ValidateTemplateParameterUsage(template *api.Template) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	declared := sets.NewString()
+	for _, param := range template.Parameters {
+		declared.Insert(param.Name)
+	}
+	for i, obj := range template.Objects {
+		objErrs := fielderrors.ValidationErrorList{}
+		reported := sets.NewString()
+		for _, name := range parameterReferences(obj) {
+			if declared.Has(name) || reported.Has(name) || ReservedParameterNames.Has(name) {
+				continue
+			}
+			reported.Insert(name)
+			objErrs = append(objErrs, fielderrors.NewFieldInvalid("", "${"+name+"}", fmt.Sprintf("references parameter %s which is not declared", name)))
+		}
+		allErrs = append(allErrs, objErrs.PrefixIndex(i).Prefix("objects")...)
+	}
+	return allErrs
+}
+
+// ValidateTemplateParameterConsistency tests that the Template parameters do
+// not combine fields in contradictory ways. Existing templates rely on some of
+// these combinations being tolerated when processed, so like
+// ValidateTemplateParameterUsage this is a lint that is not part of
+// ValidateTemplate.
+func ValidateTemplateParameterConsistency(template *api.Template) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	for i := range template.Parameters {
+		allErrs = append(allErrs, validateParameterConsistency(&template.Parameters[i]).PrefixIndex(i).Prefix("parameters")...)
+	}
+	return allErrs
+}
+
+func validateParameterConsistency(param *api.Parameter) fielderrors.ValidationErrorList {
+	allErrs := fielderrors.ValidationErrorList{}
+	if len(param.Generate) != 0 && param.Required {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("required", param.Required, "a generated parameter always has a value and does not need to be required"))
+	}
+	if len(param.Generate) != 0 && len(param.Value) != 0 {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("generate", param.Generate, "the generator is ignored when a value is set, keep either the value or the generator"))
+	}
+	return allErrs
+}
+
+// parameterReferences returns the names of the parameters referenced by the
+// string fields of the object, in the order they appear.
+func parameterReferences(obj runtime.Object) []string {
+	names := []string{}
+	collect := func(in string) {
+		for _, match := range parameterReferenceExp.FindAllStringSubmatch(in, -1) {
+			names = append(names, match[1])
+		}
+	}
+	if unknown, ok := obj.(*runtime.Unknown); ok {
+		collect(string(unknown.RawJSON))
+		return names
+	}
+	stringreplace.VisitObjectStrings(obj, func(in string) string {
+		collect(in)
+		return in
+	})
+	return names
+}
@@ -1,11 +1,13 @@
 package validation
 
 import (
+	"strings"
 	"testing"
 
 	kapi "k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/util/errors"
+	"k8s.io/kubernetes/pkg/util/fielderrors"
 
 	"github.com/openshift/origin/pkg/template/api"
 )
@@ -44,6 +46,56 @@ func TestValidateParameter(t *testing.T) {
 	}
 }
 
+func TestValidateParameterGenerateExpression(t *testing.T) {
+	var tests = []struct {
+		From            string
+		IsValidExpected bool
+	}{
+		{"[a-z]{8}", true},
+		{"test[0-9]{1}x", true},
+		{"[z-a]{8}", false},
+		{"[a-z]{256}", false},
+	}
+
+	for _, test := range tests {
+		param := &api.Parameter{Name: "PASSWORD", Generate: "expression", From: test.From}
+		errs := ValidateParameter(param)
+		if test.IsValidExpected && len(errs) != 0 {
+			t.Errorf("Expected zero validation errors on expression %q, got %v", test.From, errs)
+		}
+		if !test.IsValidExpected && (len(errs) != 1 || errs[0].(*fielderrors.ValidationError).Field != "from") {
+			t.Errorf("Expected a validation error on from for expression %q, got %v", test.From, errs)
+		}
+	}
+}
+
+func TestValidateParameterReservedName(t *testing.T) {
+	if errs := ValidateParameter(makeParameter("NAME", "1")); len(errs) != 0 {
+		t.Errorf("Expected zero validation errors on a normal parameter name, got %v", errs)
+	}
+	errs := ValidateParameter(makeParameter("DOLLAR", "1"))
+	if len(errs) != 1 || errs[0].(*fielderrors.ValidationError).Field != "name" {
+		t.Errorf("Expected a validation error on the name of a reserved parameter, got %v", errs)
+	}
+}
+
+func TestValidateParameterValueLength(t *testing.T) {
+	tests := map[int]bool{
+		0:                           true,
+		MaxParameterValueLength:     true,
+		MaxParameterValueLength + 1: false,
+	}
+	for length, isValidExpected := range tests {
+		errs := ValidateParameter(makeParameter("VALUE", strings.Repeat("a", length)))
+		if isValidExpected && len(errs) != 0 {
+			t.Errorf("Expected zero validation errors on a value of %d bytes, got %v", length, errs)
+		}
+		if !isValidExpected && (len(errs) != 1 || errs[0].(*fielderrors.ValidationError).Type != fielderrors.ValidationErrorTypeTooLong) {
+			t.Errorf("Expected a too long validation error on a value of %d bytes, got %v", length, errs)
+		}
+	}
+}
+
 func TestValidateProcessTemplate(t *testing.T) {
 	var tests = []struct {
 		template        *api.Template
@@ -190,3 +242,143 @@ func TestValidateTemplate(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateTemplateParameterUsage(t *testing.T) {
+	var tests = []struct {
+		template       *api.Template
+		expectedFields []string
+	}{
+		{ // Template with all references declared, should pass
+			&api.Template{
+				Parameters: []api.Parameter{
+					*(makeParameter("NAME", "1")),
+					*(makeParameter("PORT", "8080")),
+				},
+				Objects: []runtime.Object{
+					&kapi.Service{
+						ObjectMeta: kapi.ObjectMeta{Name: "${NAME}"},
+					},
+					&runtime.Unknown{RawJSON: []byte(`{"kind":"Service","metadata":{"name":"${NAME}-${PORT}"}}`)},
+				},
+			},
+			nil,
+		},
+		{ // Template referencing a reserved parameter, should pass
+			&api.Template{
+				Objects: []runtime.Object{
+					&kapi.Service{
+						ObjectMeta: kapi.ObjectMeta{Name: "${DOLLAR}{NAME}"},
+					},
+				},
+			},
+			nil,
+		},
+		{ // Template with an undeclared reference, should fail on the object
+			&api.Template{
+				Parameters: []api.Parameter{
+					*(makeParameter("NAME", "1")),
+				},
+				Objects: []runtime.Object{
+					&kapi.Service{
+						ObjectMeta: kapi.ObjectMeta{Name: "${NAME}"},
+					},
+					&kapi.Service{
+						ObjectMeta: kapi.ObjectMeta{
+							Name:   "${UNDECLARED}",
+							Labels: map[string]string{"name": "${UNDECLARED}"},
+						},
+					},
+					&runtime.Unknown{RawJSON: []byte(`{"kind":"Service","metadata":{"name":"${OTHER}"}}`)},
+				},
+			},
+			[]string{"objects[1]", "objects[2]"},
+		},
+	}
+
+	for i, test := range tests {
+		errs := ValidateTemplateParameterUsage(test.template)
+		if len(errs) != len(test.expectedFields) {
+			t.Errorf("%d: Unexpected error list: %v", i, errors.NewAggregate(errs))
+			continue
+		}
+		for j, err := range errs {
+			if field := err.(*fielderrors.ValidationError).Field; field != test.expectedFields[j] {
+				t.Errorf("%d: Expected error on %s, got %s", i, test.expectedFields[j], field)
+			}
+		}
+	}
+}
+
+func TestValidateTemplateParameterConsistency(t *testing.T) {
+	var tests = []struct {
+		parameter      api.Parameter
+		expectedFields []string
+	}{
+		{ // Generated parameter, should pass
+			api.Parameter{Name: "PASSWORD", Generate: "expression", From: "[a-z]{8}"},
+			nil,
+		},
+		{ // Required parameter without a generator, should pass
+			api.Parameter{Name: "USERNAME", Required: true},
+			nil,
+		},
+		{ // Generated parameter marked required, should fail
+			api.Parameter{Name: "PASSWORD", Generate: "expression", From: "[a-z]{8}", Required: true},
+			[]string{"parameters[0].required"},
+		},
+		{ // Parameter with only a value, should pass
+			api.Parameter{Name: "USERNAME", Value: "admin"},
+			nil,
+		},
+		{ // Parameter with both a value and a generator, should fail
+			api.Parameter{Name: "PASSWORD", Value: "secret", Generate: "expression", From: "[a-z]{8}"},
+			[]string{"parameters[0].generate"},
+		},
+	}
+
+	for i, test := range tests {
+		template := &api.Template{Parameters: []api.Parameter{test.parameter}}
+		errs := ValidateTemplateParameterConsistency(template)
+		if len(errs) != len(test.expectedFields) {
+			t.Errorf("%d: Unexpected error list: %v", i, errors.NewAggregate(errs))
+			continue
+		}
+		for j, err := range errs {
+			if field := err.(*fielderrors.ValidationError).Field; field != test.expectedFields[j] {
+				t.Errorf("%d: Expected error on %s, got %s", i, test.expectedFields[j], field)
+			}
+		}
+	}
+}
+
+func TestValidateTemplateNamespacedOnly(t *testing.T) {
+	defer func(old bool) { NamespacedOnly = old }(NamespacedOnly)
+	template := &api.Template{
+		ObjectMeta: kapi.ObjectMeta{Name: "template", Namespace: kapi.NamespaceDefault},
+		Objects: []runtime.Object{
+			&kapi.Service{ObjectMeta: kapi.ObjectMeta{Name: "frontend"}},
+			&kapi.Namespace{ObjectMeta: kapi.ObjectMeta{Name: "myproject"}},
+			&runtime.Unknown{RawJSON: []byte(`{"kind":"PersistentVolume","metadata":{"name":"data"}}`)},
+		},
+	}
+
+	if errs := ValidateTemplate(template); len(errs) != 0 {
+		t.Fatalf("Unexpected errors without NamespacedOnly: %v", errors.NewAggregate(errs))
+	}
+
+	NamespacedOnly = true
+	errs := ValidateTemplate(template)
+	expected := []struct{ field, name string }{{"objects[1]", "myproject"}, {"objects[2]", "data"}}
+	if len(errs) != len(expected) {
+		t.Fatalf("Unexpected error list: %v", errors.NewAggregate(errs))
+	}
+	for i, err := range errs {
+		validationErr := err.(*fielderrors.ValidationError)
+		if validationErr.Field != expected[i].field {
+			t.Errorf("Expected error on %s, got %s", expected[i].field, validationErr.Field)
+		}
+		if !strings.Contains(validationErr.Detail, expected[i].name) {
+			t.Errorf("Expected error to name %q, got %q", expected[i].name, validationErr.Detail)
+		}
+	}
+}
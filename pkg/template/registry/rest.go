@@ -2,6 +2,7 @@ package registry
 
 import (
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/golang/glog"
@@ -48,10 +49,24 @@ func (s *REST) Create(ctx kapi.Context, obj runtime.Object) (runtime.Object, err
 		"expression": generator.NewExpressionValueGenerator(rand.New(rand.NewSource(time.Now().UnixNano()))),
 	}
 	processor := template.NewProcessor(generators)
+	if ctx != nil {
+		processor.Namespace = kapi.NamespaceValue(ctx)
+	}
 	if errs := processor.Process(tpl); len(errs) > 0 {
 		glog.V(1).Infof(utilerr.NewAggregate(errs).Error())
 		return nil, errors.NewInvalid("template", tpl.Name, errs)
 	}
+	if len(processor.Warnings) > 0 {
+		warnings := make([]string, 0, len(processor.Warnings))
+		for _, warning := range processor.Warnings {
+			warnings = append(warnings, warning.Error())
+		}
+		if tpl.Annotations == nil {
+			tpl.Annotations = make(map[string]string)
+		}
+		tpl.Annotations[api.WarningsAnnotation] = strings.Join(warnings, "\n")
+		glog.V(2).Infof("template %s: %v", tpl.Name, utilerr.NewAggregate(processor.Warnings))
+	}
 
 	return tpl, nil
 }
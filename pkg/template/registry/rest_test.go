@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"strings"
 	"testing"
 
 	kapi "k8s.io/kubernetes/pkg/api"
@@ -156,3 +157,39 @@ func TestNewRESTTemplateLabelsList(t *testing.T) {
 		}
 	}
 }
+
+func TestNewRESTNamespaceWarning(t *testing.T) {
+	storage := NewREST()
+	obj, err := storage.Create(kapi.WithNamespace(kapi.NewContext(), "default"), &template.Template{
+		ObjectMeta: kapi.ObjectMeta{
+			Name: "test",
+		},
+		Objects: []runtime.Object{
+			&kapi.Service{
+				ObjectMeta: kapi.ObjectMeta{
+					Name:      "test-service",
+					Namespace: "somevalue",
+				},
+				Spec: kapi.ServiceSpec{
+					Ports: []kapi.ServicePort{
+						{
+							Port:     80,
+							Protocol: kapi.ProtocolTCP,
+						},
+					},
+					SessionAffinity: kapi.ServiceAffinityNone,
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config, ok := obj.(*template.Template)
+	if !ok {
+		t.Fatalf("unexpected return object: %#v", obj)
+	}
+	if warnings := config.Annotations[template.WarningsAnnotation]; !strings.Contains(warnings, "metadata.namespace") || !strings.Contains(warnings, `"default"`) {
+		t.Errorf("expected a namespace warning in the %s annotation, got %q", template.WarningsAnnotation, warnings)
+	}
+}
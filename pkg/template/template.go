@@ -1,15 +1,20 @@
 package template
 
 import (
+	"encoding/base64"
 	"fmt"
+	"reflect"
 	"regexp"
-	"strings"
+	"sort"
 
 	"k8s.io/kubernetes/pkg/api/meta"
 	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/util/fielderrors"
+	"k8s.io/kubernetes/pkg/util/sets"
+	kvalidation "k8s.io/kubernetes/pkg/util/validation"
 
 	"github.com/openshift/origin/pkg/template/api"
+	templatevalidation "github.com/openshift/origin/pkg/template/api/validation"
 	. "github.com/openshift/origin/pkg/template/generator"
 	"github.com/openshift/origin/pkg/util"
 	"github.com/openshift/origin/pkg/util/stringreplace"
@@ -20,6 +25,12 @@ var parameterExp = regexp.MustCompile(`\$\{([a-zA-Z0-9\_]+)\}`)
 // Processor process the Template into the List with substituted parameters
 type Processor struct {
 	Generators map[string]Generator
+	// Namespace is the namespace the processed objects will be created in. If
+	// empty, the namespace of the processed Template is used.
+	Namespace string
+	// Warnings holds diagnostics about the last processed Template that do not
+	// prevent its objects from being created.
+	Warnings fielderrors.ValidationErrorList
 }
 
 // NewProcessor creates new Processor and initializes its set of generators.
@@ -28,16 +39,27 @@ func NewProcessor(generators map[string]Generator) *Processor {
 }
 
 // Process transforms Template object into List object. It generates
-// Parameter values using the defined set of generators first, and then it
+// Parameter values using the defined set of generators first, resolves
+// Parameter values that reference other Parameters, and then it
 // substitutes all Parameter expression occurrences with their corresponding
 // values (currently in the containers' Environment variables only).
 func (p *Processor) Process(template *api.Template) fielderrors.ValidationErrorList {
 	templateErrors := fielderrors.ValidationErrorList{}
+	p.Warnings = fielderrors.ValidationErrorList{}
+
+	targetNamespace := p.Namespace
+	if len(targetNamespace) == 0 {
+		targetNamespace = template.Namespace
+	}
 
 	if err, badParam := p.GenerateParameterValues(template); err != nil {
 		return append(templateErrors.Prefix("Template"), fielderrors.NewFieldInvalid("parameters", *badParam, err.Error()))
 	}
 
+	if err, badParam := ResolveParameterReferences(template); err != nil {
+		return append(templateErrors.Prefix("Template"), fielderrors.NewFieldInvalid("parameters", *badParam, err.Error()))
+	}
+
 	for i, item := range template.Objects {
 		if obj, ok := item.(*runtime.Unknown); ok {
 			// TODO: use runtime.DecodeList when it returns ValidationErrorList
@@ -49,15 +71,35 @@ func (p *Processor) Process(template *api.Template) fielderrors.ValidationErrorL
 			item = decodedObj
 		}
 
+		parameterizedKeys := parameterizedSecretDataKeys(item)
+		parameterizedSelectors := parameterizedLabelSelectors(item)
 		newItem, err := p.SubstituteParameters(template.Parameters, item)
 		if err != nil {
 			util.ReportError(&templateErrors, i, *fielderrors.NewFieldInvalid("parameters", template.Parameters, err.Error()))
 		}
+		data := secretData(newItem)
+		for _, key := range parameterizedKeys {
+			if value, _ := data[key].(string); !isBase64(value) {
+				util.ReportError(&templateErrors, i, *fielderrors.NewFieldInvalid(fmt.Sprintf("data[%s]", key), "", "the substituted parameter value is not valid base64, secret data values must be base64 encoded, use stringData to provide the value unencoded"))
+			}
+		}
+		selectors := labelSelectors(newItem)
+		for _, field := range parameterizedSelectors {
+			for _, err := range validateLabelSelector(selectors[field]) {
+				util.ReportError(&templateErrors, i, *fielderrors.NewFieldInvalid(field, err.BadValue, err.Detail))
+			}
+		}
 		// If an object definition's metadata includes a namespace field, the field will be stripped out of
 		// the definition during template instantiation.  This is necessary because all objects created during
 		// instantiation are placed into the target namespace, so it would be invalid for the object to declare
 		//a different namespace.
+		if namespace := objectNamespace(newItem); len(namespace) > 0 && namespace != targetNamespace {
+			util.ReportError(&p.Warnings, i, *fielderrors.NewFieldInvalid("metadata.namespace", namespace, fmt.Sprintf("the namespace is cleared during processing, the object will be created in the target namespace %q", targetNamespace)))
+		}
 		stripNamespace(newItem)
+		if hasStatus(newItem) {
+			util.ReportError(&p.Warnings, i, *fielderrors.NewFieldInvalid("status", "", "the status is set by the system and is ignored when the object is created"))
+		}
 		if err := util.AddObjectLabels(newItem, template.ObjectLabels); err != nil {
 			util.ReportError(&templateErrors, i, *fielderrors.NewFieldInvalid("labels", err, "label could not be applied"))
 		}
@@ -67,6 +109,154 @@ func (p *Processor) Process(template *api.Template) fielderrors.ValidationErrorL
 	return templateErrors
 }
 
+// secretData returns the data of an unstructured Secret object, or nil if obj
+// is not one. Typed Secrets hold their data as bytes, which are never
+// substituted.
+func secretData(obj runtime.Object) map[string]interface{} {
+	unstruct, ok := obj.(*runtime.Unstructured)
+	if !ok || unstruct.Object == nil || unstruct.Object["kind"] != "Secret" {
+		return nil
+	}
+	data, _ := unstruct.Object["data"].(map[string]interface{})
+	return data
+}
+
+// parameterizedSecretDataKeys returns the sorted keys of the data of a Secret
+// object whose values reference a parameter.
+func parameterizedSecretDataKeys(obj runtime.Object) []string {
+	keys := []string{}
+	for key, value := range secretData(obj) {
+		if s, ok := value.(string); ok && parameterExp.MatchString(s) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// labelSelectorFields are the fields holding a map of labels that selects
+// other objects.
+var labelSelectorFields = sets.NewString("selector", "replicaSelector", "matchLabels")
+
+// labelSelectors returns the label selectors of an unstructured object, keyed
+// by their field path. Typed objects are not substituted into by the
+// processor, so they are not inspected.
+func labelSelectors(obj runtime.Object) map[string]map[string]interface{} {
+	selectors := map[string]map[string]interface{}{}
+	if unstruct, ok := obj.(*runtime.Unstructured); ok && unstruct.Object != nil {
+		collectLabelSelectors("", unstruct.Object, selectors)
+	}
+	return selectors
+}
+
+func collectLabelSelectors(path string, obj map[string]interface{}, selectors map[string]map[string]interface{}) {
+	for key, value := range obj {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		field := key
+		if len(path) != 0 {
+			field = path + "." + key
+		}
+		// a selector with matchLabels holds its labels one level down
+		if _, hasMatchLabels := m["matchLabels"]; labelSelectorFields.Has(key) && !hasMatchLabels {
+			selectors[field] = m
+			continue
+		}
+		collectLabelSelectors(field, m, selectors)
+	}
+}
+
+// parameterizedLabelSelectors returns the sorted field paths of the label
+// selectors of obj whose values reference a parameter. Map keys are not
+// substituted, so they are not considered.
+func parameterizedLabelSelectors(obj runtime.Object) []string {
+	fields := []string{}
+	for field, selector := range labelSelectors(obj) {
+		for _, value := range selector {
+			if s, _ := value.(string); parameterExp.MatchString(s) {
+				fields = append(fields, field)
+				break
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// validateLabelSelector checks that every key of the selector is a valid label
+// name and every value a non-empty label value. An empty value is valid in a
+// label, but in a substituted selector it usually means a parameter was left
+// empty.
+func validateLabelSelector(selector map[string]interface{}) []*fielderrors.ValidationError {
+	errs := []*fielderrors.ValidationError{}
+	keys := []string{}
+	for key := range selector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, _ := selector[key].(string)
+		switch {
+		case !kvalidation.IsQualifiedName(key):
+			errs = append(errs, fielderrors.NewFieldInvalid("", key, "the substituted selector key is not a valid label name"))
+		case len(value) == 0:
+			errs = append(errs, fielderrors.NewFieldInvalid("", key, "the substituted selector value is empty"))
+		case !kvalidation.IsValidLabelValue(value):
+			errs = append(errs, fielderrors.NewFieldInvalid("", value, "the substituted selector value is not a valid label value"))
+		}
+	}
+	return errs
+}
+
+// isBase64 returns true if s is valid standard base64 encoded data.
+func isBase64(s string) bool {
+	_, err := base64.StdEncoding.DecodeString(s)
+	return err == nil
+}
+
+// objectNamespace returns the namespace declared by obj, or an empty string if
+// it declares none.
+func objectNamespace(obj runtime.Object) string {
+	if itemMeta, err := meta.Accessor(obj); err == nil {
+		return itemMeta.Namespace()
+	}
+	if unstruct, ok := obj.(*runtime.Unstructured); ok && unstruct.Object != nil {
+		if obj, ok := unstruct.Object["metadata"]; ok {
+			if m, ok := obj.(map[string]interface{}); ok {
+				namespace, _ := m["namespace"].(string)
+				return namespace
+			}
+			return ""
+		}
+		namespace, _ := unstruct.Object["namespace"].(string)
+		return namespace
+	}
+	return ""
+}
+
+// hasStatus returns true if obj has a non-empty status, which usually means it
+// was copied from an export of a live object.
+func hasStatus(obj runtime.Object) bool {
+	if unstruct, ok := obj.(*runtime.Unstructured); ok {
+		switch status := unstruct.Object["status"].(type) {
+		case nil:
+			return false
+		case map[string]interface{}:
+			return len(status) != 0
+		default:
+			return true
+		}
+	}
+	v := reflect.Indirect(reflect.ValueOf(obj))
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	status := v.FieldByName("Status")
+	return status.IsValid() && !reflect.DeepEqual(status.Interface(), reflect.Zero(status.Type()).Interface())
+}
+
 func stripNamespace(obj runtime.Object) {
 	// Remove namespace from the item
 	if itemMeta, err := meta.Accessor(obj); err == nil {
@@ -117,25 +307,114 @@ func GetParameterByName(t *api.Template, name string) *api.Parameter {
 // Example of Parameter expression:
 //   - ${PARAMETER_NAME}
 //
+// The reserved ${DOLLAR} parameter is substituted with a literal "$", so that
+// ${DOLLAR}{PARAMETER_NAME} is left in the object as ${PARAMETER_NAME}.
 func (p *Processor) SubstituteParameters(params []api.Parameter, item runtime.Object) (runtime.Object, error) {
 	// Make searching for given parameter name/value more effective
-	paramMap := make(map[string]string, len(params))
+	paramMap := make(map[string]string, len(params)+1)
 	for _, param := range params {
 		paramMap[param.Name] = param.Value
 	}
+	paramMap[templatevalidation.DollarParameterName] = "$"
 
 	stringreplace.VisitObjectStrings(item, func(in string) string {
-		for _, match := range parameterExp.FindAllStringSubmatch(in, -1) {
-			if len(match) > 1 {
-				if paramValue, found := paramMap[match[1]]; found {
-					in = strings.Replace(in, match[0], paramValue, 1)
+		return substituteParameterValues(in, paramMap)
+	})
+
+	return item, nil
+}
+
+// substituteParameterValues replaces every ${PARAMETER_NAME} expression in the
+// given string that refers to a known parameter with its value. The values are
+// not searched for further expressions.
+func substituteParameterValues(in string, paramMap map[string]string) string {
+	return parameterExp.ReplaceAllStringFunc(in, func(ref string) string {
+		if paramValue, found := paramMap[parameterExp.FindStringSubmatch(ref)[1]]; found {
+			return paramValue
+		}
+		return ref
+	})
+}
+
+// ResolveParameterReferences substitutes references to other parameters in
+// the Value of each Parameter of the given Template, so that a value such as
+// "${BASE}-suffix" is expanded before the objects are processed. The references
+// are checked for cycles before anything is substituted, and then every
+// parameter is resolved after the parameters it refers to. A resolved value
+// may not be longer than MaxParameterValueLength, which keeps a chain of
+// references from growing a value exponentially. If the references form a
+// cycle or a value is too long, the parameter that caused the error is
+// returned along with the error message.
+func ResolveParameterReferences(t *api.Template) (error, *api.Parameter) {
+	indexes := make(map[string]int, len(t.Parameters))
+	for i, param := range t.Parameters {
+		indexes[param.Name] = i
+	}
+
+	// order the parameters after the parameters they refer to, rejecting
+	// cycles before anything is substituted
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(t.Parameters))
+	order := make([]int, 0, len(t.Parameters))
+	var visit func(i int) (error, *api.Parameter)
+	visit = func(i int) (error, *api.Parameter) {
+		param := &t.Parameters[i]
+		state[i] = visiting
+		for _, match := range parameterExp.FindAllStringSubmatch(param.Value, -1) {
+			j, found := indexes[match[1]]
+			if !found {
+				continue
+			}
+			switch state[j] {
+			case visiting:
+				return fmt.Errorf("template.parameters[%v]: parameter %s has a cyclic reference to parameter %s", i, param.Name, match[1]), param
+			case unvisited:
+				if err, badParam := visit(j); err != nil {
+					return err, badParam
 				}
 			}
 		}
-		return in
-	})
+		state[i] = visited
+		order = append(order, i)
+		return nil, nil
+	}
+	for i := range t.Parameters {
+		if state[i] == unvisited {
+			if err, badParam := visit(i); err != nil {
+				return err, badParam
+			}
+		}
+	}
 
-	return item, nil
+	for _, i := range order {
+		param := &t.Parameters[i]
+		matches := parameterExp.FindAllStringSubmatch(param.Value, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		// the referenced parameters are resolved, so the length of the result
+		// is known before it is built
+		length := len(param.Value)
+		for _, match := range matches {
+			if j, found := indexes[match[1]]; found {
+				length += len(t.Parameters[j].Value) - len(match[0])
+			}
+		}
+		if length > templatevalidation.MaxParameterValueLength {
+			return fmt.Errorf("template.parameters[%v]: the resolved value of parameter %s is longer than %d bytes", i, param.Name, templatevalidation.MaxParameterValueLength), param
+		}
+		param.Value = parameterExp.ReplaceAllStringFunc(param.Value, func(ref string) string {
+			if j, found := indexes[parameterExp.FindStringSubmatch(ref)[1]]; found {
+				return t.Parameters[j].Value
+			}
+			return ref
+		})
+	}
+	return nil, nil
 }
 
 // GenerateParameterValues generates Value for each Parameter of the given
@@ -8,13 +8,16 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	_ "k8s.io/kubernetes/pkg/api/latest"
 	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/util/fielderrors"
 
 	"github.com/openshift/origin/pkg/api/latest"
 	"github.com/openshift/origin/pkg/api/v1beta3"
 	"github.com/openshift/origin/pkg/template/api"
+	templatevalidation "github.com/openshift/origin/pkg/template/api/validation"
 	"github.com/openshift/origin/pkg/template/generator"
 )
 
@@ -171,6 +174,43 @@ func TestProcessValueEscape(t *testing.T) {
 	}
 }
 
+func TestProcessDollarParameter(t *testing.T) {
+	var template api.Template
+	if err := latest.Codec.DecodeInto([]byte(`{
+		"kind":"Template", "apiVersion":"v1",
+		"objects": [
+			{
+				"kind": "Service", "apiVersion": "v1beta3",
+				"metadata": {
+					"labels": {
+						"key1": "${VALUE}",
+						"key2": "${DOLLAR}{VALUE}"
+					}
+				}
+			}
+		]
+	}`), &template); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	processor := NewProcessor(map[string]generator.Generator{})
+	AddParameter(&template, makeParameter("VALUE", "1", "", false))
+
+	errs := processor.Process(&template)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected error: %v", errs)
+	}
+	result, err := v1beta3.Codec.Encode(&template)
+	if err != nil {
+		t.Fatalf("unexpected error during encoding Config: %#v", err)
+	}
+	expect := `{"kind":"Template","apiVersion":"v1beta3","metadata":{"creationTimestamp":null},"objects":[{"apiVersion":"v1beta3","kind":"Service","metadata":{"labels":{"key1":"1","key2":"${VALUE}"}}}],"parameters":[{"name":"VALUE","value":"1"}]}`
+	stringResult := strings.TrimSpace(string(result))
+	if expect != stringResult {
+		t.Errorf("unexpected output: %s", util.StringDiff(expect, stringResult))
+	}
+}
+
 var trailingWhitespace = regexp.MustCompile(`\n\s*`)
 
 func TestEvaluateLabels(t *testing.T) {
@@ -367,3 +407,285 @@ func TestProcessTemplateParameters(t *testing.T) {
 		t.Errorf("unexpected output: %s", util.StringDiff(string(exp), string(result)))
 	}
 }
+
+func TestProcessNestedParameterReferences(t *testing.T) {
+	var template api.Template
+	if err := latest.Codec.DecodeInto([]byte(`{
+		"kind":"Template", "apiVersion":"v1",
+		"objects": [
+			{
+				"kind": "Service", "apiVersion": "v1beta3",
+				"metadata": {
+					"labels": {
+						"key1": "${NAME}"
+					}
+				}
+			}
+		]
+	}`), &template); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	processor := NewProcessor(map[string]generator.Generator{})
+
+	AddParameter(&template, makeParameter("NAME", "${APP}-frontend", "", false))
+	AddParameter(&template, makeParameter("APP", "${BASE}-app", "", false))
+	AddParameter(&template, makeParameter("BASE", "base", "", false))
+
+	errs := processor.Process(&template)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected error: %v", errs)
+	}
+	result, err := v1beta3.Codec.Encode(&template)
+	if err != nil {
+		t.Fatalf("unexpected error during encoding Config: %#v", err)
+	}
+	expect := `{"kind":"Template","apiVersion":"v1beta3","metadata":{"creationTimestamp":null},"objects":[{"apiVersion":"v1beta3","kind":"Service","metadata":{"labels":{"key1":"base-app-frontend"}}}],"parameters":[{"name":"NAME","value":"base-app-frontend"},{"name":"APP","value":"base-app"},{"name":"BASE","value":"base"}]}`
+	stringResult := strings.TrimSpace(string(result))
+	if expect != stringResult {
+		t.Errorf("unexpected output: %s", util.StringDiff(expect, stringResult))
+	}
+}
+
+func TestResolveParameterReferencesCycle(t *testing.T) {
+	tests := map[string][]api.Parameter{
+		"self reference": {
+			makeParameter("NAME", "${NAME}-suffix", "", false),
+		},
+		"two parameter cycle": {
+			makeParameter("FIRST", "${SECOND}", "", false),
+			makeParameter("SECOND", "${FIRST}", "", false),
+		},
+	}
+	for name, params := range tests {
+		template := api.Template{Parameters: params}
+		err, badParam := ResolveParameterReferences(&template)
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+			continue
+		}
+		if badParam == nil || !strings.Contains(err.Error(), "cyclic reference") {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+}
+
+func TestResolveParameterReferencesGrowth(t *testing.T) {
+	// a chain of parameters each doubling the previous one
+	chain := []api.Parameter{makeParameter("P0", "x", "", false)}
+	for i := 1; i < 40; i++ {
+		chain = append(chain, makeParameter(fmt.Sprintf("P%d", i), fmt.Sprintf("${P%d}${P%d}", i-1, i-1), "", false))
+	}
+	tests := map[string]struct {
+		params []api.Parameter
+		expect string
+	}{
+		"doubling self reference": {
+			params: []api.Parameter{
+				makeParameter("A", "${A}${A}", "", false),
+				makeParameter("B", "${A}${A}", "", false),
+				makeParameter("C", "${B}${B}", "", false),
+				makeParameter("D", "${C}${C}", "", false),
+				makeParameter("E", "${D}${D}", "", false),
+				makeParameter("F", "${E}${E}", "", false),
+			},
+			expect: "cyclic reference",
+		},
+		"doubling chain": {
+			params: chain,
+			expect: "is longer than",
+		},
+	}
+	for name, test := range tests {
+		template := api.Template{Parameters: test.params}
+		done := make(chan error, 1)
+		go func() {
+			err, _ := ResolveParameterReferences(&template)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err == nil || !strings.Contains(err.Error(), test.expect) {
+				t.Errorf("%s: expected an error containing %q, got %v", name, test.expect, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: resolving the parameter references did not finish", name)
+		}
+		for _, param := range template.Parameters {
+			if len(param.Value) > templatevalidation.MaxParameterValueLength {
+				t.Errorf("%s: parameter %s grew to %d bytes", name, param.Name, len(param.Value))
+			}
+		}
+	}
+}
+
+func TestProcessSecretDataParameters(t *testing.T) {
+	tests := map[string]struct {
+		value       string
+		expectError bool
+	}{
+		"base64 value": {value: "c2VjcmV0"},
+		"raw value":    {value: "not base64!", expectError: true},
+	}
+	for desc, test := range tests {
+		var template api.Template
+		if err := latest.Codec.DecodeInto([]byte(`{
+			"kind":"Template", "apiVersion":"v1",
+			"objects": [
+				{
+					"kind": "Secret", "apiVersion": "v1",
+					"metadata": {"name": "db"},
+					"data": {
+						"password": "${PASSWORD}",
+						"username": "YWRtaW4="
+					}
+				}
+			]
+		}`), &template); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		AddParameter(&template, makeParameter("PASSWORD", test.value, "", false))
+
+		errs := NewProcessor(map[string]generator.Generator{}).Process(&template)
+		if !test.expectError {
+			if len(errs) != 0 {
+				t.Errorf("%s: unexpected errors: %v", desc, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 {
+			t.Errorf("%s: expected one error, got %v", desc, errs)
+			continue
+		}
+		if err := errs[0].(*fielderrors.ValidationError); err.Field != "item[0].data[password]" || !strings.Contains(err.Detail, "base64") || !strings.Contains(err.Detail, "stringData") {
+			t.Errorf("%s: unexpected error: %v", desc, err)
+		}
+	}
+}
+
+func TestProcessNamespaceWarnings(t *testing.T) {
+	tests := map[string]struct {
+		namespace     string
+		expectWarning bool
+	}{
+		"other namespace":  {namespace: "somevalue", expectWarning: true},
+		"target namespace": {namespace: "default"},
+		"no namespace":     {},
+	}
+	for desc, test := range tests {
+		var template api.Template
+		if err := latest.Codec.DecodeInto([]byte(`{
+			"kind":"Template", "apiVersion":"v1",
+			"objects": [
+				{
+					"kind": "Service", "apiVersion": "v1",
+					"metadata": {"name": "${NAME}-tester", "namespace": "`+test.namespace+`"}
+				}
+			]
+		}`), &template); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		AddParameter(&template, makeParameter("NAME", "test", "", false))
+
+		processor := NewProcessor(map[string]generator.Generator{})
+		processor.Namespace = "default"
+		if errs := processor.Process(&template); len(errs) != 0 {
+			t.Errorf("%s: unexpected errors: %v", desc, errs)
+			continue
+		}
+		if !test.expectWarning {
+			if len(processor.Warnings) != 0 {
+				t.Errorf("%s: unexpected warnings: %v", desc, processor.Warnings)
+			}
+			continue
+		}
+		if len(processor.Warnings) != 1 {
+			t.Errorf("%s: expected one warning, got %v", desc, processor.Warnings)
+			continue
+		}
+		if warning := processor.Warnings[0].(*fielderrors.ValidationError); warning.Field != "item[0].metadata.namespace" || warning.BadValue != test.namespace {
+			t.Errorf("%s: unexpected warning: %v", desc, warning)
+		}
+		// the namespace is still cleared
+		if namespace := objectNamespace(template.Objects[0]); namespace != "" {
+			t.Errorf("%s: expected namespace to be cleared, got %q", desc, namespace)
+		}
+	}
+}
+
+func TestProcessStatusWarnings(t *testing.T) {
+	tests := map[string]struct {
+		object        string
+		expectWarning bool
+	}{
+		"clean object": {
+			object: `{"kind": "Service", "apiVersion": "v1", "metadata": {"name": "frontend"}}`,
+		},
+		"empty status": {
+			object: `{"kind": "Service", "apiVersion": "v1", "metadata": {"name": "frontend"}, "status": {}}`,
+		},
+		"status-bearing object": {
+			object:        `{"kind": "Service", "apiVersion": "v1", "metadata": {"name": "frontend"}, "status": {"loadBalancer": {"ingress": [{"ip": "1.2.3.4"}]}}}`,
+			expectWarning: true,
+		},
+	}
+	for desc, test := range tests {
+		var template api.Template
+		if err := latest.Codec.DecodeInto([]byte(`{"kind":"Template", "apiVersion":"v1", "objects": [`+test.object+`]}`), &template); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		processor := NewProcessor(map[string]generator.Generator{})
+		if errs := processor.Process(&template); len(errs) != 0 {
+			t.Errorf("%s: unexpected errors: %v", desc, errs)
+			continue
+		}
+		if !test.expectWarning {
+			if len(processor.Warnings) != 0 {
+				t.Errorf("%s: unexpected warnings: %v", desc, processor.Warnings)
+			}
+			continue
+		}
+		if len(processor.Warnings) != 1 || processor.Warnings[0].(*fielderrors.ValidationError).Field != "item[0].status" {
+			t.Errorf("%s: expected one status warning, got %v", desc, processor.Warnings)
+		}
+	}
+}
+
+func TestProcessLabelSelectorParameters(t *testing.T) {
+	tests := map[string]struct {
+		value       string
+		expectError bool
+	}{
+		"valid value":   {value: "frontend"},
+		"empty value":   {value: "", expectError: true},
+		"invalid value": {value: "front end", expectError: true},
+	}
+	for desc, test := range tests {
+		var template api.Template
+		if err := latest.Codec.DecodeInto([]byte(`{
+			"kind":"Template", "apiVersion":"v1",
+			"objects": [
+				{
+					"kind": "Service", "apiVersion": "v1",
+					"metadata": {"name": "frontend"},
+					"spec": {"selector": {"name": "${NAME}", "tier": "web"}}
+				}
+			]
+		}`), &template); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		AddParameter(&template, makeParameter("NAME", test.value, "", false))
+
+		errs := NewProcessor(map[string]generator.Generator{}).Process(&template)
+		if !test.expectError {
+			if len(errs) != 0 {
+				t.Errorf("%s: unexpected errors: %v", desc, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || errs[0].(*fielderrors.ValidationError).Field != "item[0].spec.selector" {
+			t.Errorf("%s: expected one spec.selector error, got %v", desc, errs)
+		}
+	}
+}
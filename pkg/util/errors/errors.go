@@ -2,16 +2,35 @@ package errors
 
 import "strings"
 
-import kapierrors "k8s.io/kubernetes/pkg/api/errors"
+import (
+	kapierrors "k8s.io/kubernetes/pkg/api/errors"
+	kutilerrors "k8s.io/kubernetes/pkg/util/errors"
+	"k8s.io/kubernetes/pkg/util/fielderrors"
+)
 
-// TolerateNotFoundError tolerates 'not found' errors
+// TolerateNotFoundError tolerates 'not found' errors, including errors that
+// wrap a 'not found' error.
 func TolerateNotFoundError(err error) error {
-	if kapierrors.IsNotFound(err) {
-		return nil
+	for cause := err; cause != nil; cause = unwrap(cause) {
+		if kapierrors.IsNotFound(cause) {
+			return nil
+		}
 	}
 	return err
 }
 
+// unwrap returns the error wrapped by err, or nil if err does not wrap another
+// error.
+func unwrap(err error) error {
+	wrapper, ok := err.(interface {
+		Unwrap() error
+	})
+	if !ok {
+		return nil
+	}
+	return wrapper.Unwrap()
+}
+
 // ErrorToSentence will capitalize the first letter of the error
 // message and add a period to the end if one is not present.
 func ErrorToSentence(err error) string {
@@ -25,3 +44,36 @@ func ErrorToSentence(err error) string {
 	}
 	return msg
 }
+
+// FlattenAggregate returns the leaf errors of err, recursively expanding any
+// nested aggregates. Errors with the same message as an earlier one are
+// dropped, so the result is suitable for logging.
+func FlattenAggregate(err error) []error {
+	result := []error{}
+	seen := map[string]bool{}
+	var flatten func(error)
+	flatten = func(err error) {
+		if err == nil {
+			return
+		}
+		if agg, ok := err.(kutilerrors.Aggregate); ok {
+			for _, e := range agg.Errors() {
+				flatten(e)
+			}
+			return
+		}
+		if msg := err.Error(); !seen[msg] {
+			seen[msg] = true
+			result = append(result, err)
+		}
+	}
+	flatten(err)
+	return result
+}
+
+// WithField reports err as an invalid value of the field at the given path, so
+// errors from outside validation can be returned alongside field errors. The
+// message of err becomes the detail of the result.
+func WithField(field string, err error) *fielderrors.ValidationError {
+	return fielderrors.NewFieldInvalid(field, "", err.Error())
+}
@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	kapierrors "k8s.io/kubernetes/pkg/api/errors"
+	kutilerrors "k8s.io/kubernetes/pkg/util/errors"
+	"k8s.io/kubernetes/pkg/util/fielderrors"
+)
+
+func TestTolerateNotFoundError(t *testing.T) {
+	notFound := kapierrors.NewNotFound("buildconfig", "myconfig")
+	other := errors.New("connection refused")
+	wrappedOther := fmt.Errorf("getting config: %w", other)
+	tests := map[string]struct {
+		err      error
+		expected error
+	}{
+		"nil":                 {},
+		"not found":           {err: notFound},
+		"wrapped not found":   {err: fmt.Errorf("getting config: %w", notFound)},
+		"doubly wrapped":      {err: fmt.Errorf("instantiating: %w", fmt.Errorf("getting config: %w", notFound))},
+		"other error":         {err: other, expected: other},
+		"wrapped other error": {err: wrappedOther, expected: wrappedOther},
+	}
+	for desc, test := range tests {
+		if err := TolerateNotFoundError(test.err); err != test.expected {
+			t.Errorf("%s: expected %v, got %v", desc, test.expected, err)
+		}
+	}
+}
+
+func TestFlattenAggregate(t *testing.T) {
+	a, b, c := errors.New("a"), errors.New("b"), errors.New("c")
+	tests := map[string]struct {
+		err      error
+		expected []error
+	}{
+		"nil": {
+			expected: []error{},
+		},
+		"plain error": {
+			err:      a,
+			expected: []error{a},
+		},
+		"nested aggregates": {
+			err: kutilerrors.NewAggregate([]error{
+				a,
+				kutilerrors.NewAggregate([]error{b, kutilerrors.NewAggregate([]error{c})}),
+			}),
+			expected: []error{a, b, c},
+		},
+		"duplicate leaves": {
+			err: kutilerrors.NewAggregate([]error{
+				a,
+				kutilerrors.NewAggregate([]error{errors.New("a"), b}),
+				b,
+			}),
+			expected: []error{a, b},
+		},
+	}
+	for desc, test := range tests {
+		if actual := FlattenAggregate(test.err); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected %v, got %v", desc, test.expected, actual)
+		}
+	}
+}
+
+func TestWithField(t *testing.T) {
+	err := WithField("spec.source.git.uri", errors.New("unable to resolve host"))
+	if err.Type != fielderrors.ValidationErrorTypeInvalid {
+		t.Errorf("expected an invalid field error, got %s", err.Type)
+	}
+	if err.Field != "spec.source.git.uri" {
+		t.Errorf("expected field spec.source.git.uri, got %s", err.Field)
+	}
+	if err.Detail != "unable to resolve host" {
+		t.Errorf("expected the original message as detail, got %q", err.Detail)
+	}
+	if prefixed := (fielderrors.ValidationErrorList{err}).Prefix("items[0]"); prefixed[0].(*fielderrors.ValidationError).Field != "items[0].spec.source.git.uri" {
+		t.Errorf("expected the field to be prefixable, got %v", prefixed[0])
+	}
+}